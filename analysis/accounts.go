@@ -0,0 +1,451 @@
+package analysis
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"password-analyzer/utils"
+)
+
+// ParsePwdump reads a pwdump-style file (`[domain\]username:rid:lmhash:nthash:::`)
+// and returns one utils.Account per valid line. Hashes are lower-cased so they
+// can be joined with cracked plaintexts regardless of the tool that produced
+// them. History lines written by secretsdump -history (`username_historyN`)
+// are attached to their account instead of being returned as accounts, and
+// the `(status=...)` and `(pwdLastSet=...)` suffixes added by -user-status
+// and -pwd-last-set are stored on the account.
+// Malformed lines are skipped silently.
+func ParsePwdump(hashFile string) ([]utils.Account, error) {
+	f, err := os.Open(hashFile)
+	if err != nil {
+		return nil, fmt.Errorf("[!][ParsePwdump] cannot open %s: %w", hashFile, err)
+	}
+	defer f.Close()
+
+	var accounts []utils.Account
+	history := make(map[string][]historyLine)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		parts := strings.Split(line, ":")
+		if len(parts) < 4 {
+			continue // skip malformed lines
+		}
+
+		if name, index, ok := splitHistory(parts[0]); ok {
+			history[name] = append(history[name], historyLine{index: index, ntHash: strings.ToLower(parts[3])})
+			continue
+		}
+
+		account := utils.Account{
+			Username: parts[0],
+			LMHash:   strings.ToLower(parts[2]),
+			NTHash:   strings.ToLower(parts[3]),
+		}
+		if idx := strings.LastIndex(account.Username, "\\"); idx != -1 {
+			account.Domain = account.Username[:idx]
+			account.Username = account.Username[idx+1:]
+		}
+		account.RID, _ = strconv.Atoi(parts[1])
+		parseMetadata(line, &account)
+
+		accounts = append(accounts, account)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("[!][ParsePwdump] scan error: %w", err)
+	}
+
+	attachHistory(accounts, history)
+	return accounts, nil
+}
+
+// metadataRegex matches the `(key=value)` suffixes secretsdump appends after
+// the hashes, e.g. `::: (pwdLastSet=2023-01-15 10:22) (status=Enabled)`.
+var metadataRegex = regexp.MustCompile(`\((\w+)=([^)]*)\)`)
+
+// pwdLastSetLayouts lists the date formats accepted for pwdLastSet.
+var pwdLastSetLayouts = []string{"2006-01-02 15:04", "2006-01-02 15:04:05", "2006-01-02"}
+
+// parseMetadata stores the account status and password last-set date found
+// at the end of a secretsdump line. Unknown keys and dates are ignored.
+func parseMetadata(line string, account *utils.Account) {
+	_, suffix, found := strings.Cut(line, ":::")
+	if !found {
+		return
+	}
+
+	for _, match := range metadataRegex.FindAllStringSubmatch(suffix, -1) {
+		value := strings.TrimSpace(match[2])
+		switch strings.ToLower(match[1]) {
+		case "status":
+			account.Status = value
+		case "pwdlastset":
+			if strings.EqualFold(value, "never") {
+				account.NeverSet = true
+				continue
+			}
+			for _, layout := range pwdLastSetLayouts {
+				if t, err := time.Parse(layout, value); err == nil {
+					account.PwdLastSet = t
+					break
+				}
+			}
+		}
+	}
+}
+
+// ExcludeDisabled removes the accounts flagged as disabled by secretsdump and
+// returns the enabled ones. The plaintexts of passwords whose NT hash only
+// belongs to disabled accounts are removed as well, so that weak passwords
+// of disabled accounts do not weigh on the statistics.
+func ExcludeDisabled(accounts []utils.Account, passwords []string) ([]utils.Account, []string) {
+	return excludeAccounts(accounts, passwords, utils.Account.Disabled)
+}
+
+// ExcludeMachines removes the machine accounts (see Classify) the same way
+// ExcludeDisabled removes disabled accounts: their random passwords would
+// otherwise dilute the statistics.
+func ExcludeMachines(accounts []utils.Account, passwords []string) ([]utils.Account, []string) {
+	return excludeAccounts(accounts, passwords, utils.Account.Machine)
+}
+
+// excludeAccounts removes the accounts for which exclude returns true, along
+// with the plaintexts whose NT hash only belongs to removed accounts.
+func excludeAccounts(accounts []utils.Account, passwords []string, exclude func(utils.Account) bool) ([]utils.Account, []string) {
+	var kept []utils.Account
+	keptHashes := make(map[string]bool)
+	excludedHashes := make(map[string]bool)
+	for _, account := range accounts {
+		if exclude(account) {
+			excludedHashes[account.NTHash] = true
+			continue
+		}
+		kept = append(kept, account)
+		keptHashes[account.NTHash] = true
+	}
+	if len(excludedHashes) == 0 || passwords == nil {
+		return kept, passwords
+	}
+
+	var keptPasswords []string
+	for _, plain := range passwords {
+		hash := NtlmHash(plain)
+		if excludedHashes[hash] && !keptHashes[hash] {
+			continue
+		}
+		keptPasswords = append(keptPasswords, plain)
+	}
+	return kept, keptPasswords
+}
+
+// historyLine is a single `username_historyN` entry of a secretsdump file.
+type historyLine struct {
+	index  int
+	ntHash string
+}
+
+// splitHistory recognises the `username_historyN` entries written by
+// secretsdump -history and returns the account name and the history index.
+func splitHistory(username string) (string, int, bool) {
+	name, suffix, found := strings.Cut(username, "_history")
+	if !found || name == "" {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(suffix)
+	if err != nil {
+		return "", 0, false
+	}
+	return name, index, true
+}
+
+// attachHistory stores the history lines of every account, most recent
+// first. secretsdump reads ntPwdHistory, whose first entry mirrors the
+// current password: it is dropped when it equals the current NT hash.
+func attachHistory(accounts []utils.Account, history map[string][]historyLine) {
+	for i := range accounts {
+		lines := history[accounts[i].Name()]
+		sort.Slice(lines, func(a, b int) bool { return lines[a].index < lines[b].index })
+		for j, line := range lines {
+			if j == 0 && line.ntHash == accounts[i].NTHash {
+				continue
+			}
+			accounts[i].History = append(accounts[i].History, utils.HistoryEntry{NTHash: line.ntHash})
+		}
+	}
+}
+
+// LoadPotfile reads cracked NT hashes and returns them as a map of
+// lower-case NT hash to plaintext. The following line formats are accepted:
+//
+//   - hashcat potfile / --show output: `nthash:plaintext`
+//   - John the Ripper pot file:        `$NT$nthash:plaintext`
+//   - hashcat --show --username:       `username:nthash:plaintext`
+//
+// Plaintexts written by hashcat as `$HEX[...]` are decoded. Lines that do not
+// carry an NT hash (LM halves, other hash types, …) are ignored.
+func LoadPotfile(potFile string) (map[string]string, error) {
+	f, err := os.Open(potFile)
+	if err != nil {
+		return nil, fmt.Errorf("[!][LoadPotfile] cannot open %s: %w", potFile, err)
+	}
+	defer f.Close()
+
+	cracked := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		hash, plain, ok := parsePotLine(line)
+		if !ok {
+			continue
+		}
+		cracked[hash] = decodeHexPlain(plain)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("[!][LoadPotfile] scan error: %w", err)
+	}
+
+	return cracked, nil
+}
+
+// parsePotLine splits a single potfile line into its NT hash and plaintext.
+// The plaintext is everything after the hash separator, so passwords that
+// contain ':' are preserved.
+func parsePotLine(line string) (string, string, bool) {
+	first, rest, found := strings.Cut(line, ":")
+	if !found {
+		return "", "", false
+	}
+
+	// hash:plain (hashcat) or $NT$hash:plain (John)
+	if hash := strings.TrimPrefix(first, "$NT$"); isNTHash(hash) {
+		return strings.ToLower(hash), rest, true
+	}
+
+	// username:hash:plain (hashcat --show --username)
+	second, plain, found := strings.Cut(rest, ":")
+	if found && isNTHash(second) {
+		return strings.ToLower(second), plain, true
+	}
+
+	return "", "", false
+}
+
+// isNTHash reports whether s looks like a hex-encoded NT hash.
+func isNTHash(s string) bool {
+	if len(s) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// decodeHexPlain converts hashcat's `$HEX[...]` notation back to the raw
+// plaintext. Any other value is returned unchanged.
+func decodeHexPlain(plain string) string {
+	if !strings.HasPrefix(plain, "$HEX[") || !strings.HasSuffix(plain, "]") {
+		return plain
+	}
+	decoded, err := hex.DecodeString(plain[len("$HEX[") : len(plain)-1])
+	if err != nil {
+		return plain
+	}
+	return string(decoded)
+}
+
+// CrackedPasswords returns the plaintext of every cracked account, one entry
+// per account, ready to be fed to AnalyzePasswordList.
+func CrackedPasswords(accounts []utils.Account) []string {
+	var passwords []string
+	for _, account := range accounts {
+		if account.Cracked {
+			passwords = append(passwords, account.Password)
+		}
+	}
+	return passwords
+}
+
+// JoinCracked attaches the plaintexts found in cracked (NT hash → plaintext)
+// to the matching accounts and returns the list of cracked passwords, one
+// entry per cracked account, ready to be fed to AnalyzePasswordList.
+func JoinCracked(accounts []utils.Account, cracked map[string]string) []string {
+	var passwords []string
+	for i := range accounts {
+		for j := range accounts[i].History {
+			if plain, ok := cracked[accounts[i].History[j].NTHash]; ok {
+				accounts[i].History[j].Password = plain
+				accounts[i].History[j].Cracked = true
+			}
+		}
+
+		plain, ok := cracked[accounts[i].NTHash]
+		if !ok {
+			continue
+		}
+		accounts[i].Password = plain
+		accounts[i].Cracked = true
+		passwords = append(passwords, plain)
+	}
+	return passwords
+}
+
+// AttributePasswords recomputes the NT hash of every plaintext of the
+// password file with NtlmHash and attaches it to the accounts of the dump
+// sharing that hash. Plaintexts that match no NT hash of the dump are
+// returned as orphans: they usually mean that the password file and the
+// hash file do not come from the same extract.
+func AttributePasswords(accounts []utils.Account, passwords []string) []string {
+	known := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		known[account.NTHash] = true
+		for _, previous := range account.History {
+			known[previous.NTHash] = true
+		}
+	}
+
+	cracked := make(map[string]string)
+	seen := make(map[string]bool)
+	var orphans []string
+	for _, plain := range passwords {
+		if plain == "" || seen[plain] {
+			continue
+		}
+		seen[plain] = true
+
+		hash := NtlmHash(plain)
+		if !known[hash] {
+			orphans = append(orphans, plain)
+			continue
+		}
+		cracked[hash] = plain
+	}
+
+	JoinCracked(accounts, cracked)
+	return orphans
+}
+
+// AssignReuseGroups numbers the groups of accounts sharing the same NT hash,
+// largest group first, and stores that number in Account.ReuseGroup so
+// exporters can show which accounts use the same password. Accounts whose
+// hash is unique keep a ReuseGroup of 0.
+func AssignReuseGroups(accounts []utils.Account) {
+	members := make(map[string]int)
+	for _, account := range accounts {
+		members[account.NTHash]++
+	}
+
+	var shared []string
+	for hash, count := range members {
+		if count > 1 {
+			shared = append(shared, hash)
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if members[shared[i]] != members[shared[j]] {
+			return members[shared[i]] > members[shared[j]]
+		}
+		return shared[i] < shared[j]
+	})
+
+	groups := make(map[string]int, len(shared))
+	for i, hash := range shared {
+		groups[hash] = i + 1
+	}
+	for i := range accounts {
+		accounts[i].ReuseGroup = groups[accounts[i].NTHash]
+	}
+}
+
+// ReuseClusters groups the accounts sharing an NT hash into clusters, in the
+// order of their reuse group so the largest cluster comes first. Accounts
+// must already carry their reuse group (see AssignReuseGroups).
+func ReuseClusters(accounts []utils.Account) []utils.ReuseCluster {
+	var clusters []utils.ReuseCluster
+	for _, account := range accounts {
+		if account.ReuseGroup == 0 {
+			continue
+		}
+		for len(clusters) < account.ReuseGroup {
+			clusters = append(clusters, utils.ReuseCluster{})
+		}
+
+		cluster := &clusters[account.ReuseGroup-1]
+		cluster.Group = account.ReuseGroup
+		cluster.NTHash = account.NTHash
+		cluster.Accounts = append(cluster.Accounts, account.Name())
+		cluster.Size++
+		if account.Cracked {
+			cluster.Cracked = true
+			cluster.Password = account.Password
+		}
+	}
+	return clusters
+}
+
+// WeightByAccounts counts the length, complexity, pattern and token
+// distributions once per cracked account rather than once per line of the
+// password file: a password shared by 40 accounts then weighs 40 times as
+// much as a password used once. Patterns and tokens are counted for the keys
+// of stats only, so that every report lists the same rows in both columns;
+// an account counts for a token when one of the words of its password
+// contains it. perAccount tells that stats were already computed once per
+// account (-pot): the weighted statistics are then the same. Accounts must
+// already carry their plaintext (see JoinCracked and AttributePasswords).
+func WeightByAccounts(accounts []utils.Account, stats utils.Stats, perAccount bool) utils.WeightedStats {
+	if perAccount {
+		return utils.WeightedStats{
+			Enabled:      true,
+			PerAccount:   true,
+			CrackedCount: stats.CrackedCount,
+			Lengths:      stats.Lengths,
+			Complexity:   stats.Complexity,
+			Patterns:     stats.Patterns,
+			TokenCount:   stats.TokenCount,
+		}
+	}
+
+	weighted := utils.WeightedStats{
+		Enabled:    true,
+		Lengths:    make(map[int]int),
+		Complexity: make(map[int]int),
+		Patterns:   make(map[string]int),
+		TokenCount: make(map[string]int),
+	}
+	for _, password := range CrackedPasswords(accounts) {
+		// Empty lines are not counted by AnalyzePasswordList either
+		if password == "" {
+			continue
+		}
+		length, category := countCategories(password)
+		weighted.CrackedCount++
+		weighted.Lengths[length]++
+		weighted.Complexity[category]++
+		if pattern := passwordPattern(password); stats.Patterns[pattern] > 0 {
+			weighted.Patterns[pattern]++
+		}
+		tokens := passwordTokens(password)
+		for key := range stats.TokenCount {
+			if slices.ContainsFunc(tokens, func(token string) bool { return strings.Contains(token, key) }) {
+				weighted.TokenCount[key]++
+			}
+		}
+	}
+	return weighted
+}
@@ -0,0 +1,191 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"password-analyzer/utils"
+)
+
+const passwordHash = "8846f7eaee8fb117ad06bdd830b7586c" // NtlmHash("password")
+
+func TestNtlmHash(t *testing.T) {
+	if got := NtlmHash("password"); got != passwordHash {
+		t.Errorf("NtlmHash(password) = %s, want %s", got, passwordHash)
+	}
+	if got := NtlmHash(""); got != emptyNTHash {
+		t.Errorf("NtlmHash(\"\") = %s, want %s", got, emptyNTHash)
+	}
+}
+
+func TestParsePotLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		hash  string
+		plain string
+		ok    bool
+	}{
+		{"hashcat", passwordHash + ":password", passwordHash, "password", true},
+		{"upper-case hash", strings.ToUpper(passwordHash) + ":password", passwordHash, "password", true},
+		{"john", "$NT$" + passwordHash + ":password", passwordHash, "password", true},
+		{"username", "CORP\\jdoe:" + passwordHash + ":password", passwordHash, "password", true},
+		{"colon in password", passwordHash + ":pass:word:", passwordHash, "pass:word:", true},
+		{"colon in password with username", "jdoe:" + passwordHash + ":a:b", passwordHash, "a:b", true},
+		{"empty password", passwordHash + ":", passwordHash, "", true},
+		{"hex plaintext kept encoded", passwordHash + ":$HEX[70617373]", passwordHash, "$HEX[70617373]", true},
+		{"no separator", passwordHash, "", "", false},
+		{"LM half", "aad3b435b51404ee:PASSWOR", "", "", false},
+		{"other hash type", "5f4dcc3b5aa765d61d8327deb882cf99zz:password", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, plain, ok := parsePotLine(tt.line)
+			if hash != tt.hash || plain != tt.plain || ok != tt.ok {
+				t.Errorf("parsePotLine(%q) = %q, %q, %v, want %q, %q, %v", tt.line, hash, plain, ok, tt.hash, tt.plain, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDecodeHexPlain(t *testing.T) {
+	tests := []struct {
+		plain string
+		want  string
+	}{
+		{"$HEX[70617373]", "pass"},
+		{"$HEX[70613a7373]", "pa:ss"},
+		{"$HEX[c3a9c3a9]", "éé"},
+		{"$HEX[]", ""},
+		{"$HEX[zz]", "$HEX[zz]"},
+		{"$HEX[7061", "$HEX[7061"},
+		{"password", "password"},
+	}
+	for _, tt := range tests {
+		if got := decodeHexPlain(tt.plain); got != tt.want {
+			t.Errorf("decodeHexPlain(%q) = %q, want %q", tt.plain, got, tt.want)
+		}
+	}
+}
+
+func TestLoadPotfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashcat.pot")
+	content := strings.Join([]string{
+		NtlmHash("Spring2024!") + ":Spring2024!",
+		"$NT$" + NtlmHash("pa:ss") + ":$HEX[70613a7373]",
+		"CORP\\jdoe:" + strings.ToUpper(passwordHash) + ":password\r",
+		"aad3b435b51404ee:PASSWOR",
+		"",
+	}, "\n")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cracked, err := LoadPotfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		NtlmHash("Spring2024!"): "Spring2024!",
+		NtlmHash("pa:ss"):       "pa:ss",
+		passwordHash:            "password",
+	}
+	if len(cracked) != len(want) {
+		t.Errorf("LoadPotfile returned %d hashes, want %d: %v", len(cracked), len(want), cracked)
+	}
+	for hash, plain := range want {
+		if cracked[hash] != plain {
+			t.Errorf("cracked[%s] = %q, want %q", hash, cracked[hash], plain)
+		}
+	}
+}
+
+func TestParsePwdump(t *testing.T) {
+	current := NtlmHash("Spring2024!")
+	previous := NtlmHash("Spring2023!")
+	path := filepath.Join(t.TempDir(), "hashes.txt")
+	content := strings.Join([]string{
+		"CORP\\jdoe:1101:aad3b435b51404eeaad3b435b51404ee:" + strings.ToUpper(current) + "::: (pwdLastSet=2026-09-01 10:00) (status=Enabled)",
+		"CORP\\jdoe_history1:1101:aad3b435b51404eeaad3b435b51404ee:" + previous + ":::",
+		"CORP\\jdoe_history0:1101:aad3b435b51404eeaad3b435b51404ee:" + current + ":::",
+		"CORP\\Guest:501:aad3b435b51404eeaad3b435b51404ee:" + emptyNTHash + "::: (pwdLastSet=never) (status=Disabled)",
+		"svc_sql:1106:AAD3B435B51404EEAAD3B435B51404EE:" + passwordHash + "::: (pwdLastSet=2024-02-11)",
+		"malformed line",
+		"",
+	}, "\n")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	accounts, err := ParsePwdump(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []utils.Account{
+		{
+			Domain: "CORP", Username: "jdoe", RID: 1101,
+			LMHash: emptyLMHash, NTHash: current,
+			History:    []utils.HistoryEntry{{NTHash: previous}},
+			Status:     "Enabled",
+			PwdLastSet: time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			Domain: "CORP", Username: "Guest", RID: 501,
+			LMHash: emptyLMHash, NTHash: emptyNTHash,
+			Status: "Disabled", NeverSet: true,
+		},
+		{
+			Username: "svc_sql", RID: 1106,
+			LMHash: emptyLMHash, NTHash: passwordHash,
+			PwdLastSet: time.Date(2024, 2, 11, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	if len(accounts) != len(want) {
+		t.Fatalf("ParsePwdump returned %d accounts, want %d: %+v", len(accounts), len(want), accounts)
+	}
+	for i := range want {
+		got := accounts[i]
+		if got.Name() != want[i].Name() || got.RID != want[i].RID || got.LMHash != want[i].LMHash || got.NTHash != want[i].NTHash ||
+			got.Status != want[i].Status || got.NeverSet != want[i].NeverSet || !got.PwdLastSet.Equal(want[i].PwdLastSet) ||
+			!slices.Equal(got.History, want[i].History) {
+			t.Errorf("account %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestAttributePasswords(t *testing.T) {
+	accounts := []utils.Account{
+		{Domain: "CORP", Username: "jdoe", NTHash: NtlmHash("Spring2024!"), History: []utils.HistoryEntry{{NTHash: NtlmHash("Spring2023!")}}},
+		{Domain: "CORP", Username: "asmith", NTHash: NtlmHash("Password1")},
+		{Domain: "CORP", Username: "bsmith", NTHash: NtlmHash("Password1")},
+		{Domain: "CORP", Username: "dlee", NTHash: NtlmHash("Zx9#kLm!Qw2$rT")},
+	}
+	passwords := []string{"Spring2024!", "Password1", "Password1", "Spring2023!", "NotInDump1", "", "NotInDump1", "Other"}
+
+	orphans := AttributePasswords(accounts, passwords)
+	if want := []string{"NotInDump1", "Other"}; !slices.Equal(orphans, want) {
+		t.Errorf("orphans = %q, want %q", orphans, want)
+	}
+
+	want := []struct {
+		password string
+		cracked  bool
+	}{
+		{"Spring2024!", true},
+		{"Password1", true},
+		{"Password1", true},
+		{"", false},
+	}
+	for i, w := range want {
+		if accounts[i].Password != w.password || accounts[i].Cracked != w.cracked {
+			t.Errorf("%s: password %q, cracked %v, want %q, %v", accounts[i].Name(), accounts[i].Password, accounts[i].Cracked, w.password, w.cracked)
+		}
+	}
+	if entry := accounts[0].History[0]; !entry.Cracked || entry.Password != "Spring2023!" {
+		t.Errorf("history of jdoe = %+v, want Spring2023! cracked", entry)
+	}
+}
@@ -0,0 +1,79 @@
+package analysis
+
+import (
+	"strings"
+
+	"password-analyzer/utils"
+)
+
+// DefaultAdminPatterns lists the usual names of the administration account
+// of a user; {user} stands for the username of the personal account.
+var DefaultAdminPatterns = []string{"adm-{user}", "adm_{user}", "{user}_adm", "{user}-adm", "{user}-admin", "a.{user}"}
+
+// Bounds used by differsBySuffix: the common base must be long enough to be
+// a real password, and the differing ends short enough to be a mere suffix.
+const (
+	suffixMinBase   = 4
+	suffixMaxLength = 4
+)
+
+// LinkAdminAccounts pairs each personal account with the administration
+// accounts of the same domain whose name matches one of patterns (see
+// DefaultAdminPatterns), case-insensitively. Pairs sharing the same NT hash,
+// other than the empty password, or whose cracked passwords only differ by a
+// suffix (Spring2024! and Spring2024!!), are returned as findings.
+func LinkAdminAccounts(accounts []utils.Account, patterns []string) utils.AdminLinks {
+	byName := make(map[string]utils.Account, len(accounts))
+	for _, account := range accounts {
+		byName[strings.ToLower(account.Name())] = account
+	}
+
+	var links utils.AdminLinks
+	for _, personal := range accounts {
+		seen := make(map[string]bool)
+		for _, pattern := range patterns {
+			pattern = strings.TrimSpace(pattern)
+			if !strings.Contains(pattern, "{user}") {
+				continue
+			}
+			name := strings.ReplaceAll(strings.ToLower(pattern), "{user}", strings.ToLower(personal.Username))
+			if personal.Domain != "" {
+				name = strings.ToLower(personal.Domain) + "\\" + name
+			}
+			admin, found := byName[name]
+			if !found || seen[name] || strings.EqualFold(admin.Name(), personal.Name()) {
+				continue
+			}
+			seen[name] = true
+			links.Pairs++
+
+			pair := utils.AdminPair{Personal: personal.Name(), Admin: admin.Name()}
+			switch {
+			case personal.NTHash == admin.NTHash && personal.NTHash != "" && personal.NTHash != emptyNTHash:
+				pair.SameHash = true
+			case personal.Cracked && admin.Cracked && differsBySuffix(personal.Password, admin.Password):
+				pair.PersonalPassword = personal.Password
+				pair.AdminPassword = admin.Password
+			default:
+				continue
+			}
+			links.Shared = append(links.Shared, pair)
+		}
+	}
+	return links
+}
+
+// differsBySuffix reports whether a and b share a base of at least
+// suffixMinBase characters and only differ by their last suffixMaxLength
+// characters at most, e.g. Password1 and Password1!, or Summer23 and Summer24.
+func differsBySuffix(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if string(ra) == string(rb) {
+		return false
+	}
+	base := 0
+	for base < len(ra) && base < len(rb) && ra[base] == rb[base] {
+		base++
+	}
+	return base >= suffixMinBase && len(ra)-base <= suffixMaxLength && len(rb)-base <= suffixMaxLength
+}
@@ -0,0 +1,48 @@
+package analysis
+
+import (
+	"testing"
+
+	"password-analyzer/utils"
+)
+
+func TestLinkAdminAccounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		personal utils.Account
+		admin    utils.Account
+		shared   bool
+	}{
+		{"same hash",
+			utils.Account{Domain: "CORP", Username: "jdoe", NTHash: passwordHash},
+			utils.Account{Domain: "CORP", Username: "adm-jdoe", NTHash: passwordHash},
+			true},
+		{"suffix",
+			utils.Account{Domain: "CORP", Username: "jdoe", NTHash: NtlmHash("Spring2024!"), Password: "Spring2024!", Cracked: true},
+			utils.Account{Domain: "CORP", Username: "adm-jdoe", NTHash: NtlmHash("Spring2024!!"), Password: "Spring2024!!", Cracked: true},
+			true},
+		{"empty password",
+			utils.Account{Domain: "CORP", Username: "jdoe", NTHash: emptyNTHash},
+			utils.Account{Domain: "CORP", Username: "adm-jdoe", NTHash: emptyNTHash},
+			false},
+		{"no hash",
+			utils.Account{Domain: "CORP", Username: "jdoe"},
+			utils.Account{Domain: "CORP", Username: "adm-jdoe"},
+			false},
+		{"different passwords",
+			utils.Account{Domain: "CORP", Username: "jdoe", NTHash: NtlmHash("Spring2024!")},
+			utils.Account{Domain: "CORP", Username: "adm-jdoe", NTHash: passwordHash},
+			false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links := LinkAdminAccounts([]utils.Account{tt.personal, tt.admin}, DefaultAdminPatterns)
+			if links.Pairs != 1 {
+				t.Errorf("Pairs = %d, want 1", links.Pairs)
+			}
+			if shared := len(links.Shared) > 0; shared != tt.shared {
+				t.Errorf("shared = %v, want %v: %+v", shared, tt.shared, links.Shared)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package analysis
+
+import (
+	"time"
+
+	"password-analyzer/utils"
+)
+
+// AgeBuckets holds the upper bounds, in days, of the password-age buckets of
+// utils.AgeStats. The last bucket gathers every older password.
+var AgeBuckets = []int{90, 180, 365, 730, 1825}
+
+// AnalyzeAge computes the password-age distribution of the accounts whose
+// pwdLastSet is known, relative to now, and lists the accounts whose password
+// was never set or is older than maxAge days.
+func AnalyzeAge(accounts []utils.Account, now time.Time, maxAge int) utils.AgeStats {
+	stats := utils.AgeStats{
+		Buckets: make([]int, len(AgeBuckets)+1),
+		MaxAge:  maxAge,
+	}
+
+	for _, account := range accounts {
+		if account.NeverSet {
+			stats.NeverSet = append(stats.NeverSet, account.Name())
+			continue
+		}
+		if account.PwdLastSet.IsZero() {
+			continue
+		}
+		stats.Known++
+
+		days := int(now.Sub(account.PwdLastSet).Hours() / 24)
+		bucket := len(AgeBuckets)
+		for i, bound := range AgeBuckets {
+			if days < bound {
+				bucket = i
+				break
+			}
+		}
+		stats.Buckets[bucket]++
+
+		if days > maxAge {
+			stats.Older = append(stats.Older, account.Name())
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,137 @@
+package analysis
+
+import (
+	"maps"
+	"slices"
+	"sort"
+
+	"password-analyzer/utils"
+)
+
+// Well-known relative identifiers of built-in domain accounts.
+const (
+	ridAdministrator = 500
+	ridGuest         = 501
+	ridKrbtgt        = 502
+	firstUserRID     = 1000 // First RID given to accounts created after the domain
+)
+
+// ridRangeCount is the number of ranges, of equal account count, the RIDs of
+// created accounts are split into by RIDRanges.
+const ridRangeCount = 5
+
+// ridRangeMinAccounts is the smallest number of accounts of a range of
+// created accounts, so that small subsets are not split into ranges of one
+// or two accounts.
+const ridRangeMinAccounts = 20
+
+// emptyNTHash is the NT hash of the empty password.
+const emptyNTHash = "31d6cfe0d16ae931b73c59d7e0c089c0"
+
+// emptyLMHash is the LM hash stored when LM storage is disabled.
+const emptyLMHash = "aad3b435b51404eeaad3b435b51404ee"
+
+// AnalyzeBuiltin checks the built-in accounts identified by their RID: Guest
+// (501) enabled with an empty password, and the NT hash of Administrator
+// (500) or krbtgt (502) shared with any other account of the hash file.
+// Guest accounts without a secretsdump status are considered enabled.
+func AnalyzeBuiltin(accounts []utils.Account) utils.BuiltinStats {
+	var stats utils.BuiltinStats
+	for _, account := range accounts {
+		switch account.RID {
+		case ridGuest:
+			if !account.Disabled() && (account.NTHash == "" || account.NTHash == emptyNTHash) {
+				stats.GuestEmpty = append(stats.GuestEmpty, account.Name())
+			}
+		case ridAdministrator:
+			if shared := sharedHash(account, accounts); len(shared.Accounts) > 0 {
+				stats.AdminReused = append(stats.AdminReused, shared)
+			}
+		case ridKrbtgt:
+			if shared := sharedHash(account, accounts); len(shared.Accounts) > 0 {
+				stats.KrbtgtReused = append(stats.KrbtgtReused, shared)
+			}
+		}
+	}
+	return stats
+}
+
+// sharedHash lists the accounts, other than builtin itself, whose NT hash is
+// the one of builtin. The empty password is left out, as in
+// CrossDomainReuse: blank and disabled accounts do not share a password.
+func sharedHash(builtin utils.Account, accounts []utils.Account) utils.SharedHash {
+	shared := utils.SharedHash{Builtin: builtin.Name()}
+	if builtin.NTHash == "" || builtin.NTHash == emptyNTHash {
+		return shared
+	}
+	for _, account := range accounts {
+		if account.NTHash == builtin.NTHash && account.Name() != builtin.Name() {
+			shared.Accounts = append(shared.Accounts, account.Name())
+		}
+	}
+	return shared
+}
+
+// RIDRanges compares the crack rate of old and recent accounts. RIDs are only
+// meaningful within a domain, so the ranges are computed per domain: built-in
+// accounts (RID below 1000) form the first range, then the accounts created
+// afterwards are sorted by RID and split into ranges holding the same number
+// of accounts, oldest first. A range holds at least ridRangeMinAccounts
+// accounts, unless the domain has fewer, and ranges of a single account,
+// whose crack rate means nothing, are left out.
+func RIDRanges(accounts []utils.Account) []utils.RIDRange {
+	byDomain := make(map[string][]utils.Account)
+	for _, account := range accounts {
+		byDomain[account.Domain] = append(byDomain[account.Domain], account)
+	}
+	domains := slices.Sorted(maps.Keys(byDomain))
+
+	var ranges []utils.RIDRange
+	for _, domain := range domains {
+		builtin := utils.RIDRange{Domain: domain}
+		var created []utils.Account
+		for _, account := range byDomain[domain] {
+			if account.RID >= firstUserRID {
+				created = append(created, account)
+				continue
+			}
+			addToRange(&builtin, account)
+		}
+		domainRanges := []utils.RIDRange{builtin}
+
+		sort.SliceStable(created, func(i, j int) bool { return created[i].RID < created[j].RID })
+		count := max(1, min(ridRangeCount, len(created)/ridRangeMinAccounts))
+		for i := 0; i < count && len(created) > 0; i++ {
+			r := utils.RIDRange{Domain: domain}
+			for _, account := range created[i*len(created)/count : (i+1)*len(created)/count] {
+				addToRange(&r, account)
+			}
+			domainRanges = append(domainRanges, r)
+		}
+
+		for _, r := range domainRanges {
+			if r.Accounts < 2 {
+				continue
+			}
+			if len(domains) == 1 {
+				r.Domain = ""
+			}
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
+
+// addToRange widens r to include the RID of account and counts it.
+func addToRange(r *utils.RIDRange, account utils.Account) {
+	if r.Accounts == 0 || account.RID < r.From {
+		r.From = account.RID
+	}
+	if r.Accounts == 0 || account.RID > r.To {
+		r.To = account.RID
+	}
+	r.Accounts++
+	if account.Cracked {
+		r.Cracked++
+	}
+}
@@ -0,0 +1,74 @@
+package analysis
+
+import (
+	"fmt"
+	"testing"
+
+	"password-analyzer/utils"
+)
+
+func TestRIDRanges(t *testing.T) {
+	var accounts []utils.Account
+	for rid := 1100; rid < 1140; rid++ {
+		accounts = append(accounts, utils.Account{Domain: "CORP", Username: fmt.Sprint(rid), RID: rid, Cracked: rid%2 == 0})
+	}
+	accounts = append(accounts,
+		utils.Account{Domain: "CORP", Username: "Administrator", RID: 500, Cracked: true},
+		utils.Account{Domain: "CORP", Username: "Guest", RID: 501},
+		utils.Account{Domain: "EXT", Username: "jdoe", RID: 1104, Cracked: true},
+		utils.Account{Domain: "EXT", Username: "esmith", RID: 1200},
+		utils.Account{Domain: "OTHER", Username: "jdoe", RID: 1101},
+	)
+
+	tests := []struct {
+		name     string
+		accounts []utils.Account
+		want     []string
+	}{
+		{"domains", accounts, []string{
+			"CORP 500 - 501 : 1 / 2",
+			"CORP 1100 - 1119 : 10 / 20",
+			"CORP 1120 - 1139 : 10 / 20",
+			"EXT 1104 - 1200 : 1 / 2",
+		}},
+		{"single domain", accounts[:40], []string{
+			"1100 - 1119 : 10 / 20",
+			"1120 - 1139 : 10 / 20",
+		}},
+		{"small subset", accounts[40:42], []string{
+			"500 - 501 : 1 / 2",
+		}},
+		{"single account", accounts[44:], nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges := RIDRanges(tt.accounts)
+			var got []string
+			for _, r := range ranges {
+				got = append(got, fmt.Sprintf("%s : %d / %d", r.Name(), r.Cracked, r.Accounts))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("RIDRanges = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeBuiltinEmptyHash(t *testing.T) {
+	accounts := []utils.Account{
+		{Domain: "CORP", Username: "Administrator", RID: 500, NTHash: emptyNTHash},
+		{Domain: "CORP", Username: "Guest", RID: 501, NTHash: emptyNTHash, Status: "Disabled"},
+		{Domain: "CORP", Username: "krbtgt", RID: 502, NTHash: passwordHash},
+		{Domain: "CORP", Username: "blank", RID: 1101, NTHash: emptyNTHash},
+		{Domain: "CORP", Username: "svc", RID: 1102, NTHash: passwordHash},
+		{Domain: "CORP", Username: "nohash", RID: 1103},
+	}
+
+	stats := AnalyzeBuiltin(accounts)
+	if len(stats.AdminReused) != 0 {
+		t.Errorf("Administrator with the empty password reported as shared: %+v", stats.AdminReused)
+	}
+	if len(stats.KrbtgtReused) != 1 || fmt.Sprint(stats.KrbtgtReused[0].Accounts) != `[CORP\svc]` {
+		t.Errorf("KrbtgtReused = %+v, want CORP\\svc", stats.KrbtgtReused)
+	}
+}
@@ -0,0 +1,139 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"password-analyzer/utils"
+)
+
+// DefaultClassRules holds the built-in classification rules: for each class,
+// the regular expressions matched case-insensitively against the bare
+// username. Accounts matching no rule belong to utils.ClassUser.
+var DefaultClassRules = map[string][]string{
+	utils.ClassMachine: {`\$$`},
+	utils.ClassService: {`^svc[_.-]`, `[_.-]svc$`},
+	utils.ClassAdmin:   {`^adm[_.-]`, `[_.-]adm$`, `-admin$`},
+}
+
+// classOrder is the precedence of the built-in classes: a machine account
+// named `adm-wks01$` is a machine account. Classes added by a rule file are
+// tried afterwards, by name.
+var classOrder = []string{utils.ClassMachine, utils.ClassService, utils.ClassAdmin}
+
+// classRule is a compiled classification rule.
+type classRule struct {
+	class string
+	regex *regexp.Regexp
+}
+
+// LoadClassRules reads a JSON rule file mapping class names to lists of
+// regular expressions, e.g. {"service": ["^svc_", "^sql"]}, and merges it
+// with DefaultClassRules: the classes listed in the file replace the
+// built-in rules of the same name, the others are kept.
+func LoadClassRules(ruleFile string) (map[string][]string, error) {
+	content, err := os.ReadFile(ruleFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var custom map[string][]string
+	if err := json.Unmarshal(content, &custom); err != nil {
+		return nil, fmt.Errorf("[!][LoadClassRules] %w", err)
+	}
+
+	rules := make(map[string][]string, len(DefaultClassRules)+len(custom))
+	for class, patterns := range DefaultClassRules {
+		rules[class] = patterns
+	}
+	for class, patterns := range custom {
+		rules[class] = patterns
+	}
+	return rules, nil
+}
+
+// compileClassRules orders and compiles rules, built-in classes first.
+func compileClassRules(rules map[string][]string) ([]classRule, error) {
+	order := append([]string(nil), classOrder...)
+	var extra []string
+	for class := range rules {
+		if !isBuiltinClass(class) {
+			extra = append(extra, class)
+		}
+	}
+	sort.Strings(extra)
+	order = append(order, extra...)
+
+	var compiled []classRule
+	for _, class := range order {
+		for _, pattern := range rules[class] {
+			regex, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				return nil, fmt.Errorf("[!][compileClassRules] class %s: %w", class, err)
+			}
+			compiled = append(compiled, classRule{class: class, regex: regex})
+		}
+	}
+	return compiled, nil
+}
+
+// isBuiltinClass reports whether class is one of the classes of classOrder.
+func isBuiltinClass(class string) bool {
+	for _, builtin := range classOrder {
+		if class == builtin {
+			return true
+		}
+	}
+	return false
+}
+
+// Classify stores in Account.Class the class of the first rule matching the
+// username of every account, or utils.ClassUser when none matches.
+func Classify(accounts []utils.Account, rules map[string][]string) error {
+	compiled, err := compileClassRules(rules)
+	if err != nil {
+		return err
+	}
+
+	for i := range accounts {
+		accounts[i].Class = utils.ClassUser
+		for _, rule := range compiled {
+			if rule.regex.MatchString(accounts[i].Username) {
+				accounts[i].Class = rule.class
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// ClassBreakdown computes the statistics of every class of accounts (see
+// SubsetStats), built-in classes first, then user accounts, then the
+// classes added by a rule file. Empty classes are left out.
+func ClassBreakdown(parent utils.Stats, accounts []utils.Account, minCharOccurences int) ([]utils.Breakdown, error) {
+	members := make(map[string][]utils.Account)
+	var extra []string
+	for _, account := range accounts {
+		if _, seen := members[account.Class]; !seen && !isBuiltinClass(account.Class) && account.Class != utils.ClassUser {
+			extra = append(extra, account.Class)
+		}
+		members[account.Class] = append(members[account.Class], account)
+	}
+	sort.Strings(extra)
+
+	var breakdown []utils.Breakdown
+	for _, class := range append(append(append([]string(nil), classOrder...), utils.ClassUser), extra...) {
+		if len(members[class]) == 0 {
+			continue
+		}
+		stats, err := SubsetStats(parent, members[class], minCharOccurences)
+		if err != nil {
+			return nil, fmt.Errorf("[!][ClassBreakdown] %w", err)
+		}
+		breakdown = append(breakdown, utils.Breakdown{Name: class, Stats: stats})
+	}
+	return breakdown, nil
+}
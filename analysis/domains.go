@@ -0,0 +1,80 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"password-analyzer/utils"
+)
+
+// DomainBreakdown computes the statistics of every domain of the hash file
+// (see SubsetStats), sorted by domain name. Accounts without a domain prefix
+// form their own subset with an empty name. It returns nil when the hash
+// file holds a single domain.
+func DomainBreakdown(parent utils.Stats, accounts []utils.Account, minCharOccurences int) ([]utils.Breakdown, error) {
+	members := make(map[string][]utils.Account)
+	var domains []string
+	for _, account := range accounts {
+		if _, seen := members[account.Domain]; !seen {
+			domains = append(domains, account.Domain)
+		}
+		members[account.Domain] = append(members[account.Domain], account)
+	}
+	if len(domains) < 2 {
+		return nil, nil
+	}
+	sort.Strings(domains)
+
+	breakdown := make([]utils.Breakdown, 0, len(domains))
+	for _, domain := range domains {
+		stats, err := SubsetStats(parent, members[domain], minCharOccurences)
+		if err != nil {
+			return nil, fmt.Errorf("[!][DomainBreakdown] %w", err)
+		}
+		breakdown = append(breakdown, utils.Breakdown{Name: domain, Stats: stats})
+	}
+	return breakdown, nil
+}
+
+// CrossDomainReuse returns the NT hashes shared by accounts of at least two
+// domains, largest group first: a password reused across a trust lets an
+// attacker pivot from one domain to another. The empty password is left out,
+// it is already reported on its own.
+func CrossDomainReuse(accounts []utils.Account) []utils.ReuseCluster {
+	byHash := make(map[string][]utils.Account)
+	for _, account := range accounts {
+		if account.NTHash == "" || account.NTHash == emptyNTHash {
+			continue
+		}
+		byHash[account.NTHash] = append(byHash[account.NTHash], account)
+	}
+
+	var clusters []utils.ReuseCluster
+	for hash, members := range byHash {
+		domains := make(map[string]bool)
+		for _, account := range members {
+			domains[account.Domain] = true
+		}
+		if len(domains) < 2 {
+			continue
+		}
+
+		cluster := utils.ReuseCluster{Group: members[0].ReuseGroup, NTHash: hash, Size: len(members)}
+		for _, account := range members {
+			cluster.Accounts = append(cluster.Accounts, account.Name())
+			if account.Cracked {
+				cluster.Cracked = true
+				cluster.Password = account.Password
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Size != clusters[j].Size {
+			return clusters[i].Size > clusters[j].Size
+		}
+		return clusters[i].NTHash < clusters[j].NTHash
+	})
+	return clusters
+}
@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"strings"
+
+	"password-analyzer/utils"
+)
+
+// AccountFindings lists the accounts of stats affected by each finding:
+// username as password, LM hash stored, empty NT hash, reused NT hash,
+// cracked password and password containing one of the top keywords. It must
+// be called before masking, keywords being searched in the plaintexts.
+func AccountFindings(stats utils.Stats, top int) []utils.Finding {
+	userAsPassword := make(map[string]bool, len(stats.Hashes.UserEqualHash))
+	for _, name := range stats.Hashes.UserEqualHash {
+		userAsPassword[name] = true
+	}
+	var tokens []string
+	for i, entry := range utils.SortMapByValueDesc(stats.TokenCount) {
+		if i == top {
+			break
+		}
+		tokens = append(tokens, entry.Key)
+	}
+
+	findings := []utils.Finding{
+		{Key: utils.FindingUserAsPassword},
+		{Key: utils.FindingLM},
+		{Key: utils.FindingEmpty},
+		{Key: utils.FindingReused},
+		{Key: utils.FindingCracked},
+		{Key: utils.FindingTopTokens, Details: []string{}},
+	}
+	for _, account := range stats.Accounts {
+		if userAsPassword[account.Name()] {
+			findings[0].Accounts = append(findings[0].Accounts, account)
+		}
+		if account.LMHash != "" && account.LMHash != emptyLMHash {
+			findings[1].Accounts = append(findings[1].Accounts, account)
+		}
+		if account.NTHash == "" || account.NTHash == emptyNTHash {
+			findings[2].Accounts = append(findings[2].Accounts, account)
+		}
+		if account.ReuseGroup > 0 {
+			findings[3].Accounts = append(findings[3].Accounts, account)
+		}
+		if account.Cracked {
+			findings[4].Accounts = append(findings[4].Accounts, account)
+			if token := matchToken(account.Password, tokens); token != "" {
+				findings[5].Accounts = append(findings[5].Accounts, account)
+				findings[5].Details = append(findings[5].Details, token)
+			}
+		}
+	}
+	return findings
+}
+
+// matchToken returns the first of tokens found in password once lowercased
+// and unleeted, as the tokens were counted, or "" if there is none.
+func matchToken(password string, tokens []string) string {
+	base := Unleet(strings.ToLower(password))
+	for _, token := range tokens {
+		if strings.Contains(base, token) {
+			return token
+		}
+	}
+	return ""
+}
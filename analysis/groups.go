@@ -0,0 +1,130 @@
+package analysis
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"password-analyzer/utils"
+)
+
+// groupHeaders lists the first-column names that mark a header row in a
+// group membership CSV.
+var groupHeaders = map[string]bool{
+	"username":       true,
+	"user":           true,
+	"samaccountname": true,
+	"member":         true,
+	"name":           true,
+}
+
+// LoadGroups reads a group membership CSV with `username,group` rows, as
+// exported from BloodHound or Get-ADGroupMember, and returns the groups of
+// every member keyed by normalised username (see groupKey). An optional
+// header row is skipped; rows with fewer than two columns are ignored.
+func LoadGroups(groupFile string) (map[string][]string, error) {
+	file, err := os.Open(groupFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	groups := make(map[string][]string)
+	first := true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("[!][LoadGroups] %w", err)
+		}
+		if first {
+			first = false
+			if groupHeaders[strings.ToLower(strings.TrimSpace(record[0]))] {
+				continue
+			}
+		}
+		if len(record) < 2 {
+			continue
+		}
+
+		key := groupKey(record[0])
+		group := strings.TrimSpace(record[1])
+		if key == "" || group == "" {
+			continue
+		}
+		groups[key] = appendUnique(groups[key], group)
+	}
+
+	return groups, nil
+}
+
+// groupKey normalises a member name so it can be looked up from a pwdump
+// account: `DOMAIN\user` is kept qualified, while `user@domain.fqdn` and bare
+// names are reduced to the username, since the DNS domain of a UPN cannot be
+// matched against the NetBIOS prefix of the hash file.
+func groupKey(member string) string {
+	member = strings.ToLower(strings.TrimSpace(member))
+	if at := strings.LastIndex(member, "@"); at >= 0 && !strings.Contains(member, "\\") {
+		member = member[:at]
+	}
+	return member
+}
+
+// appendUnique appends value to values unless it is already present.
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// TagGroups attaches to every account the groups found for it in groups
+// (see LoadGroups), matching first on `DOMAIN\username`, then on the bare
+// username. A bare name shared by accounts of several domains is ambiguous:
+// it is not applied, so that EXT\jdoe is not reported as a member of a CORP
+// group, and it is returned so that the caller can ask for a qualified name.
+// It returns a copy of the privileged accounts, in dump order.
+func TagGroups(accounts []utils.Account, groups map[string][]string) ([]utils.Account, []string) {
+	domains := make(map[string]map[string]bool)
+	for _, account := range accounts {
+		name := strings.ToLower(account.Username)
+		if domains[name] == nil {
+			domains[name] = make(map[string]bool)
+		}
+		domains[name][strings.ToLower(account.Domain)] = true
+	}
+
+	var privileged []utils.Account
+	var ambiguous []string
+	for i := range accounts {
+		accounts[i].Groups = nil
+		if accounts[i].Domain != "" {
+			accounts[i].Groups = groups[strings.ToLower(accounts[i].Name())]
+		}
+		name := strings.ToLower(accounts[i].Username)
+		if len(domains[name]) > 1 {
+			if _, found := groups[name]; found && !slices.Contains(ambiguous, name) {
+				ambiguous = append(ambiguous, name)
+			}
+		} else {
+			for _, group := range groups[name] {
+				accounts[i].Groups = appendUnique(accounts[i].Groups, group)
+			}
+		}
+		if accounts[i].Privileged() {
+			privileged = append(privileged, accounts[i])
+		}
+	}
+	return privileged, ambiguous
+}
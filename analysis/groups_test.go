@@ -0,0 +1,35 @@
+package analysis
+
+import (
+	"slices"
+	"testing"
+
+	"password-analyzer/utils"
+)
+
+func TestTagGroups(t *testing.T) {
+	groups := map[string][]string{
+		`corp\administrator`: {"Domain Admins"},
+		"adm-jdoe":           {"Domain Admins"},
+		"jdoe":               {"Domain Admins"},
+	}
+	accounts := []utils.Account{
+		{Domain: "CORP", Username: "Administrator"},
+		{Domain: "EXT", Username: "Administrator"},
+		{Domain: "CORP", Username: "adm-jdoe"},
+		{Domain: "CORP", Username: "jdoe"},
+		{Domain: "EXT", Username: "jdoe"},
+	}
+
+	privileged, ambiguous := TagGroups(accounts, groups)
+	var names []string
+	for _, account := range privileged {
+		names = append(names, account.Name())
+	}
+	if want := []string{`CORP\Administrator`, `CORP\adm-jdoe`}; !slices.Equal(names, want) {
+		t.Errorf("privileged = %q, want %q", names, want)
+	}
+	if want := []string{"jdoe"}; !slices.Equal(ambiguous, want) {
+		t.Errorf("ambiguous = %q, want %q", ambiguous, want)
+	}
+}
@@ -0,0 +1,115 @@
+package analysis
+
+import (
+	"unicode"
+
+	"password-analyzer/utils"
+)
+
+// cyclingMaxPasswords is the largest number of distinct passwords an account
+// may rotate through, current one included, to be reported as cycling.
+const cyclingMaxPasswords = 3
+
+// AnalyzeHistory inspects the previous passwords attached to each account by
+// ParsePwdump and reports:
+//
+//   - accounts whose current NT hash is also one of their previous hashes;
+//   - accounts cycling through a small set of passwords;
+//   - predictable changes between two known plaintexts, where only the digit
+//     groups changed (e.g. `Spring2023!` → `Spring2024!`).
+func AnalyzeHistory(accounts []utils.Account) utils.HistoryStats {
+	var stats utils.HistoryStats
+	for _, account := range accounts {
+		if len(account.History) == 0 {
+			continue
+		}
+		stats.Accounts++
+
+		distinct := map[string]bool{account.NTHash: true}
+		reused := false
+		for _, previous := range account.History {
+			if previous.NTHash == account.NTHash {
+				reused = true
+			}
+			distinct[previous.NTHash] = true
+		}
+		if reused {
+			stats.ReusedCurrent = append(stats.ReusedCurrent, account.Name())
+		}
+
+		total := len(account.History) + 1
+		if total >= 3 && len(distinct) < total && len(distinct) <= cyclingMaxPasswords {
+			stats.Cycling = append(stats.Cycling, account.Name())
+		}
+
+		// Walk the passwords from newest to oldest and keep the most recent
+		// predictable change.
+		newer := utils.HistoryEntry{NTHash: account.NTHash, Password: account.Password, Cracked: account.Cracked}
+		for _, older := range account.History {
+			if newer.Cracked && older.Cracked && isIncremental(older.Password, newer.Password) {
+				stats.Incremental = append(stats.Incremental, utils.HistoryChange{
+					Account:  account.Name(),
+					Previous: older.Password,
+					Current:  newer.Password,
+				})
+				break
+			}
+			newer = older
+		}
+	}
+	return stats
+}
+
+// isIncremental reports whether current only differs from previous by the
+// value of its digit groups, such as a year or a counter being bumped.
+func isIncremental(previous, current string) bool {
+	if previous == current {
+		return false
+	}
+
+	prevRuns, curRuns := digitRuns(previous), digitRuns(current)
+	if len(prevRuns) != len(curRuns) {
+		return false
+	}
+
+	changed := false
+	for i := range prevRuns {
+		prevDigits := isDigits(prevRuns[i])
+		if prevDigits != isDigits(curRuns[i]) {
+			return false
+		}
+		if prevRuns[i] == curRuns[i] {
+			continue
+		}
+		if !prevDigits {
+			return false // the non-digit part changed
+		}
+		changed = true
+	}
+	return changed
+}
+
+// digitRuns splits s into consecutive groups of digits and non-digits.
+func digitRuns(s string) []string {
+	var runs []string
+	var current []rune
+	for _, r := range s {
+		if len(current) > 0 && unicode.IsDigit(current[0]) != unicode.IsDigit(r) {
+			runs = append(runs, string(current))
+			current = current[:0]
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		runs = append(runs, string(current))
+	}
+	return runs
+}
+
+// isDigits reports whether the run produced by digitRuns is made of digits.
+func isDigits(run string) bool {
+	for _, r := range run {
+		return unicode.IsDigit(r)
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package analysis
+
+import (
+	"strings"
+
+	"password-analyzer/utils"
+)
+
+// preWin2000MaxLength is the length at which the default password of a
+// pre-Windows 2000 computer account is truncated.
+const preWin2000MaxLength = 14
+
+// PreWindows2000 returns the machine accounts (username ending with `$`)
+// whose NT hash is the one of the default password set by the "pre-Windows
+// 2000 compatible" option: the lower-case hostname without the trailing `$`,
+// truncated to 14 characters. Such accounts can be used by anyone to
+// authenticate to the domain.
+func PreWindows2000(accounts []utils.Account) []string {
+	var matches []string
+	for _, account := range accounts {
+		host, found := strings.CutSuffix(account.Username, "$")
+		if !found || host == "" {
+			continue
+		}
+		host = strings.ToLower(host)
+		if runes := []rune(host); len(runes) > preWin2000MaxLength {
+			host = string(runes[:preWin2000MaxLength])
+		}
+		if NtlmHash(host) == account.NTHash {
+			matches = append(matches, account.Name())
+		}
+	}
+	return matches
+}
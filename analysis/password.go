@@ -4,10 +4,12 @@ import (
 	"bufio"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
 	"os"
+	assets "password-analyzer"
 	"password-analyzer/utils"
 	"regexp"
 	"strings"
@@ -17,19 +19,42 @@ import (
 	"golang.org/x/crypto/md4"
 )
 
-// AnalyzePasswords scans the password file located at filename, computes a
-// broad set of statistics (length distribution, complexity, patterns, token
-// frequency, reuse, …) and returns them wrapped inside a utils.Data value
-// along with any error encountered while reading. The function expects one
-// plaintext password per line.
-func AnalyzePasswords(filename string, minCharOccurences int) (utils.Data, error) {
+// ErrTooFewPasswords is returned by AnalyzePasswordList when fewer than two
+// passwords were supplied. The statistics are still computed.
+var ErrTooFewPasswords = errors.New("Password file must contain at least 2 passwords")
+
+// ReadPasswords returns the lines of the password file located at filename,
+// one plaintext per line, without any filtering.
+func ReadPasswords(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var passwords []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		passwords = append(passwords, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return passwords, nil
+}
+
+// AnalyzePasswordList scans a list of plaintexts, one entry per cracked
+// account or per line of the password file, computes a broad set of
+// statistics (length distribution, complexity, patterns, token frequency,
+// reuse, …) and returns them wrapped inside a utils.Data value.
+func AnalyzePasswordList(passwords []string, minCharOccurences int) (utils.Data, error) {
 	// Extract “base words” exactly like Pipal’s basic checker: sequences of
 	// 4 or more alphabetic characters. Digits/symbols are ignored here – they
 	// are handled later by the deleet() transformation which converts common
 	// leet-speak characters (e.g. “0”→"o", "4"→"a") to their alphabetic
 	// equivalents.
 
-	tokenRegex := regexp.MustCompile(`[A-Za-z01345$!|@é]{4,}`)
 	data := utils.Data{
 		Stats: utils.Stats{
 			CrackedCount: 0,
@@ -42,41 +67,24 @@ func AnalyzePasswords(filename string, minCharOccurences int) (utils.Data, error
 		Labels: utils.Labels{},
 	}
 
-	file, err := os.Open(filename)
-	if err != nil {
-		return data, err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
 	lineCount := 0 // track number of non-empty password lines
-	for scanner.Scan() {
-		line := scanner.Text()
-		matchingStrings := tokenRegex.FindAllString(line, -1)
+	for _, line := range passwords {
 		if line == "" {
 			continue
 		}
 		lineCount++
 		length, category := countCategories(line)
-		var pattern []rune
 		data.Stats.Lengths[length]++
 		data.Stats.Complexity[category]++
 		data.Stats.Mostreuse[line]++
 		data.Stats.CrackedCount++
 
-		// Get password pattern (l,u,d,s)
-		for _, r := range line {
-			pattern = append(pattern, classifyChar(r))
-		}
-		data.Stats.Patterns[string(pattern)]++
+		data.Stats.Patterns[passwordPattern(line)]++
 
 		// reads passwords and counts all alphanumeric and special char tokens
-		for _, matched := range matchingStrings {
-			lowermatched := strings.ToLower(matched)
-
-			unLeeted := Unleet(lowermatched)
-			if len(unLeeted) >= minCharOccurences {
-				data.Stats.TokenCount[unLeeted]++
+		for _, token := range passwordTokens(line) {
+			if len(token) >= minCharOccurences {
+				data.Stats.TokenCount[token]++
 			}
 		}
 	}
@@ -110,7 +118,7 @@ func AnalyzePasswords(filename string, minCharOccurences int) (utils.Data, error
 
 	// Ensure the file contained at least two valid password lines to avoid downstream crashes
 	if lineCount < 2 {
-		return data, fmt.Errorf("Password file must contain at least 2 passwords")
+		return data, ErrTooFewPasswords
 	}
 
 	// total reused passwords count
@@ -121,7 +129,30 @@ func AnalyzePasswords(filename string, minCharOccurences int) (utils.Data, error
 		}
 	}
 
-	return data, scanner.Err()
+	return data, nil
+}
+
+// tokenRegex matches the words of a password, leet-speak characters included.
+var tokenRegex = regexp.MustCompile(`[A-Za-z01345$!|@é]{4,}`)
+
+// passwordTokens returns the words of a password, lower-cased and unleeted,
+// before they are merged into the keys of TokenCount.
+func passwordTokens(password string) []string {
+	var tokens []string
+	for _, matched := range tokenRegex.FindAllString(password, -1) {
+		tokens = append(tokens, Unleet(strings.ToLower(matched)))
+	}
+	return tokens
+}
+
+// passwordPattern returns the pattern of a password, one of l (lower), u
+// (upper), d (digit) or s (special) per character.
+func passwordPattern(password string) string {
+	var pattern []rune
+	for _, r := range password {
+		pattern = append(pattern, classifyChar(r))
+	}
+	return string(pattern)
 }
 
 func countCategories(password string) (int, int) {
@@ -170,52 +201,27 @@ func classifyChar(r rune) rune {
 	}
 }
 
-// AnalyzeHashes parses a pwdump-style text file whose lines follow the
-// pattern `username:rid:lmhash:nthash:::`. It returns aggregated hash
-// statistics (total, unique, reused ‑ LM presence, …). Malformed lines are
-// skipped silently.
-func AnalyzeHashes(hashFile string) (utils.HashStats, error) {
-	const emptyLM = "aad3b435b51404eeaad3b435b51404ee"   // canonical disabled LM hash
-	const emptyNTLM = "31d6cfe0d16ae931b73c59d7e0c089c0" // NTLM hash of empty string
-
-	f, err := os.Open(hashFile)
-	if err != nil {
-		return utils.HashStats{}, fmt.Errorf("[utils][ComputeHashStats] cannot open %s: %w", hashFile, err)
-	}
-	defer f.Close()
-
+// ComputeHashStats aggregates the hash statistics (total, unique, reused,
+// LM presence, empty passwords) of the given accounts. It lets callers
+// compute them on a subset of the hash file, e.g. enabled accounts only.
+func ComputeHashStats(accounts []utils.Account) utils.HashStats {
 	var stats utils.HashStats
 	ntlmSeen := make(map[string]int)
 
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-
-		parts := strings.Split(line, ":")
-		if len(parts) < 4 {
-			continue // skip malformed lines
-		}
-
-		lm := parts[2]
-		ntlm := parts[3]
+	for _, account := range accounts {
+		lm := account.LMHash
+		ntlm := account.NTHash
 
 		// NTLM accounting
-		isEmptyNTLM := ntlm == "" || strings.EqualFold(ntlm, emptyNTLM)
+		isEmptyNTLM := ntlm == "" || ntlm == emptyNTHash
 		if isEmptyNTLM {
 			stats.EmptyNTLMHashes++
 		}
 		stats.TotalNTLMHashes++
-		if _, ok := ntlmSeen[ntlm]; !ok {
-			ntlmSeen[ntlm] = 1
-		} else {
-			ntlmSeen[ntlm]++
-		}
+		ntlmSeen[ntlm]++
 
 		// LM accounting (real LM hashes present?)
-		if lm != "" && !strings.EqualFold(lm, emptyLM) {
+		if lm != "" && lm != emptyLMHash {
 			stats.IsLM++
 		}
 	}
@@ -227,13 +233,9 @@ func AnalyzeHashes(hashFile string) (utils.HashStats, error) {
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return utils.HashStats{}, fmt.Errorf("[!][utils][ComputeHashStats] scan error: %w", err)
-	}
-
 	stats.ReusedNTLMHashes = stats.TotalNTLMHashes - stats.UniqueNTLMHashes
 
-	return stats, nil
+	return stats
 }
 
 // EvaluateRisk takes a variable list of percentage metrics (password reuse,
@@ -259,13 +261,12 @@ func EvaluateRisk(lang string, percentages ...float64) (string, float64) {
 
 	filePath := fmt.Sprintf("lang/%s.json", lang)
 
-	file, err := os.Open(filePath)
+	content, err := assets.ReadFile(filePath)
 	if err != nil {
 		log.Printf("[!][EvaluateRisk] Failed to open language file: %s", err)
 	}
-	defer file.Close()
 
-	if err := json.NewDecoder(file).Decode(&riskLabels); err != nil {
+	if err := json.Unmarshal(content, &riskLabels); err != nil {
 		log.Printf("[!][EvaluateRisk]Failed to decode language JSON: %s", err)
 	}
 
@@ -281,6 +282,39 @@ func EvaluateRisk(lang string, percentages ...float64) (string, float64) {
 	}
 }
 
+// StatsRisk evaluates the risk of stats with EvaluateRisk and stores the
+// level, the score and its inputs in stats.
+func StatsRisk(lang string, stats *utils.Stats) {
+	stats.RiskInputs = RiskInputs(*stats)
+	percentages := make([]float64, len(stats.RiskInputs))
+	for i, input := range stats.RiskInputs {
+		percentages[i] = input.Value
+	}
+	stats.Risk, stats.GlobalPercent = EvaluateRisk(lang, percentages...)
+}
+
+// RiskInputs returns the metrics available for the mode of stats, averaged
+// into the risk score: reuse and LM storage in hash-only mode, plus the weak
+// complexity, short length and cracked shares otherwise (the cracked share
+// requires a hash file).
+func RiskInputs(stats utils.Stats) []utils.RiskInput {
+	inputs := []utils.RiskInput{
+		{Name: "reuse", Value: utils.Percent(stats.Hashes.ReusedNTLMHashes, stats.Hashes.TotalNTLMHashes)},
+	}
+	if stats.HashOnly {
+		return append(inputs, utils.RiskInput{Name: "lm", Value: utils.Percent(stats.Hashes.IsLM, stats.Hashes.TotalNTLMHashes)})
+	}
+
+	inputs = append(inputs,
+		utils.RiskInput{Name: "complexity", Value: utils.Percent(stats.Complexity[1]+stats.Complexity[2]+stats.Complexity[3], stats.CrackedCount)},
+		utils.RiskInput{Name: "length", Value: utils.Percent(utils.SumLengthRange(stats.Lengths, 0, 10), stats.CrackedCount)},
+	)
+	if stats.Hashes.IsHash {
+		inputs = append(inputs, utils.RiskInput{Name: "crackRate", Value: utils.Percent(stats.CrackedCount, stats.Hashes.TotalNTLMHashes)})
+	}
+	return inputs
+}
+
 // Common leet-speak substitutions
 var leetMap = map[rune]rune{
 	'0': 'o',
@@ -355,48 +389,19 @@ func NtlmHash(password string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-// This function reads a hash file (username:RID:LM:NT:::)
-// and returns the list of usernames equal to their hash.
-func UsernameAsPass(hashFile string) ([]string, error) {
-
-	file, err := os.Open(hashFile)
-	if err != nil {
-		return nil, fmt.Errorf("cannot open %s: %w", hashFile, err)
-	}
-	defer file.Close()
-
+// UsersAsPassword returns the qualified names (`DOMAIN\username`) of the
+// accounts whose NT hash is the NTLM hash of their own bare username.
+func UsersAsPassword(accounts []utils.Account) []string {
 	var matches []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-
-		parts := strings.Split(line, ":")
-		if len(parts) < 4 {
-			continue // malformed line
-		}
-
-		// Extract bare username (strip optional domain prefix)
-		account := parts[0]
-		if idx := strings.LastIndex(account, "\\"); idx != -1 {
-			account = account[idx+1:]
-		}
-
-		ntlmHash := strings.ToLower(parts[3])
-		if ntlmHash == "" {
+	for _, account := range accounts {
+		if account.NTHash == "" {
 			continue // empty NTLM field
 		}
 
-		if strings.EqualFold(NtlmHash(account), ntlmHash) {
-			matches = append(matches, account)
+		if NtlmHash(account.Username) == account.NTHash {
+			matches = append(matches, account.Name())
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("scan error: %w", err)
-	}
-
-	return matches, nil
+	return matches
 }
@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"password-analyzer/utils"
+)
+
+// SubsetStats computes, for a subset of the accounts of the hash file (e.g.
+// privileged accounts), the same statistics as the full report. Password
+// statistics are counted once per cracked account of the subset, and the
+// reuse clusters are those of parent restricted to its accounts.
+// A subset with fewer than two cracked passwords is not an error.
+func SubsetStats(parent utils.Stats, accounts []utils.Account, minCharOccurences int) (utils.Stats, error) {
+	var stats utils.Stats
+	if !parent.HashOnly {
+		data, err := AnalyzePasswordList(CrackedPasswords(accounts), minCharOccurences)
+		if err != nil && !errors.Is(err, ErrTooFewPasswords) {
+			return utils.Stats{}, fmt.Errorf("[!][SubsetStats] %w", err)
+		}
+		stats = data.Stats
+		stats.Builtin.RIDRanges = RIDRanges(accounts)
+	}
+
+	stats.HashOnly = parent.HashOnly
+	stats.Top = parent.Top
+	stats.Accounts = accounts
+	stats.Hashes = ComputeHashStats(accounts)
+	stats.Hashes.IsHash = true
+	stats.Hashes.UserEqualHash = UsersAsPassword(accounts)
+	stats.Hashes.DisabledIncluded = parent.Hashes.DisabledIncluded
+	for _, account := range accounts {
+		if account.Disabled() {
+			stats.Hashes.Disabled++
+			if account.Cracked {
+				stats.Hashes.DisabledCracked++
+			}
+		}
+	}
+	stats.Clusters = clustersOf(parent.Clusters, accounts)
+	stats.History = AnalyzeHistory(accounts)
+	stats.Age = AnalyzeAge(accounts, time.Now(), parent.Age.MaxAge)
+
+	return stats, nil
+}
+
+// clustersOf restricts the reuse clusters to the given accounts, in their
+// original order: members outside the subset are dropped, then the clusters
+// left with a single member. Reuse across domains is reported on its own
+// (see CrossDomainReuse).
+func clustersOf(clusters []utils.ReuseCluster, accounts []utils.Account) []utils.ReuseCluster {
+	members := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		members[account.Name()] = true
+	}
+
+	var kept []utils.ReuseCluster
+	for _, cluster := range clusters {
+		var names []string
+		for _, name := range cluster.Accounts {
+			if members[name] {
+				names = append(names, name)
+			}
+		}
+		if len(names) < 2 {
+			continue
+		}
+		cluster.Accounts = names
+		cluster.Size = len(names)
+		kept = append(kept, cluster)
+	}
+	return kept
+}
@@ -0,0 +1,32 @@
+package analysis
+
+import (
+	"slices"
+	"testing"
+
+	"password-analyzer/utils"
+)
+
+func TestClustersOf(t *testing.T) {
+	clusters := []utils.ReuseCluster{
+		{Group: 1, Accounts: []string{`CORP\asmith`, `CORP\bsmith`, `EXT\csmith`, `EXT\dsmith`}, Size: 4},
+		{Group: 2, Accounts: []string{`CORP\jdoe`, `EXT\jdoe`}, Size: 2},
+		{Group: 3, Accounts: []string{`CORP\elee`, `CORP\flee`}, Size: 2},
+	}
+	accounts := []utils.Account{
+		{Domain: "EXT", Username: "csmith"},
+		{Domain: "EXT", Username: "dsmith"},
+		{Domain: "EXT", Username: "jdoe"},
+	}
+
+	got := clustersOf(clusters, accounts)
+	if len(got) != 1 {
+		t.Fatalf("clustersOf returned %d clusters, want 1: %+v", len(got), got)
+	}
+	if want := []string{`EXT\csmith`, `EXT\dsmith`}; got[0].Group != 1 || got[0].Size != 2 || !slices.Equal(got[0].Accounts, want) {
+		t.Errorf("cluster = %+v, want group 1 with %q", got[0], want)
+	}
+	if clusters[0].Size != 4 || len(clusters[0].Accounts) != 4 {
+		t.Errorf("clustersOf modified the clusters of the parent: %+v", clusters[0])
+	}
+}
@@ -0,0 +1,146 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"password-analyzer/utils"
+)
+
+// trendMetric computes one key metric of an audit. Metrics that need cracked
+// passwords or a hash file are only compared when every audit has them.
+type trendMetric struct {
+	key            string
+	group          string
+	higherIsBetter bool
+	cracked        bool // Needs cracked passwords
+	hashes         bool // Needs a hash file
+	value          func(s utils.Stats) float64
+}
+
+// trendMetrics lists the metrics compared between audits, in display order.
+// Lengths and complexities are percentages of cracked passwords, the other
+// metrics percentages of NT hashes, except the risk score.
+var trendMetrics = []trendMetric{
+	{"crackRate", "global", false, true, true, func(s utils.Stats) float64 {
+		return utils.Percent(s.CrackedCount, s.Hashes.TotalNTLMHashes)
+	}},
+	{"reuse", "global", false, false, true, func(s utils.Stats) float64 {
+		return utils.Percent(s.Hashes.ReusedNTLMHashes, s.Hashes.TotalNTLMHashes)
+	}},
+	{"lm", "global", false, false, true, func(s utils.Stats) float64 {
+		return utils.Percent(s.Hashes.IsLM, s.Hashes.TotalNTLMHashes)
+	}},
+	{"risk", "global", false, false, false, func(s utils.Stats) float64 {
+		return s.GlobalPercent
+	}},
+	{"lengthShort", "length", false, true, false, func(s utils.Stats) float64 {
+		return utils.Percent(utils.SumLengthRange(s.Lengths, 0, 7), s.CrackedCount)
+	}},
+	{"length8", "length", false, true, false, func(s utils.Stats) float64 {
+		return utils.Percent(s.Lengths[8], s.CrackedCount)
+	}},
+	{"length9", "length", false, true, false, func(s utils.Stats) float64 {
+		return utils.Percent(s.Lengths[9], s.CrackedCount)
+	}},
+	{"length10", "length", false, true, false, func(s utils.Stats) float64 {
+		return utils.Percent(s.Lengths[10], s.CrackedCount)
+	}},
+	{"lengthLong", "length", true, true, false, func(s utils.Stats) float64 {
+		return utils.Percent(utils.SumLengthRange(s.Lengths, 11, 100), s.CrackedCount)
+	}},
+	{"complexity1", "complexity", false, true, false, func(s utils.Stats) float64 {
+		return utils.Percent(s.Complexity[1], s.CrackedCount)
+	}},
+	{"complexity2", "complexity", false, true, false, func(s utils.Stats) float64 {
+		return utils.Percent(s.Complexity[2], s.CrackedCount)
+	}},
+	{"complexity3", "complexity", false, true, false, func(s utils.Stats) float64 {
+		return utils.Percent(s.Complexity[3], s.CrackedCount)
+	}},
+	{"complexity4", "complexity", true, true, false, func(s utils.Stats) float64 {
+		return utils.Percent(s.Complexity[4], s.CrackedCount)
+	}},
+}
+
+// NewSnapshot returns the snapshot of a run, keeping only the aggregate
+// counters compared by trendMetrics: the accounts, hashes, reuse clusters and
+// passwords (keywords, reused passwords) are left out so that the file holds
+// no credential material.
+func NewSnapshot(date time.Time, stats utils.Stats) utils.Snapshot {
+	return utils.Snapshot{
+		Version: utils.SnapshotVersion,
+		Date:    date,
+		Stats: utils.Stats{
+			CrackedCount:  stats.CrackedCount,
+			TotalCount:    stats.TotalCount,
+			Lengths:       stats.Lengths,
+			Complexity:    stats.Complexity,
+			HashOnly:      stats.HashOnly,
+			GlobalPercent: stats.GlobalPercent,
+			Risk:          stats.Risk,
+			Hashes: utils.HashStats{
+				TotalNTLMHashes:  stats.Hashes.TotalNTLMHashes,
+				UniqueNTLMHashes: stats.Hashes.UniqueNTLMHashes,
+				ReusedNTLMHashes: stats.Hashes.ReusedNTLMHashes,
+				IsLM:             stats.Hashes.IsLM,
+				IsHash:           stats.Hashes.IsHash,
+				EmptyNTLMHashes:  stats.Hashes.EmptyNTLMHashes,
+			},
+		},
+	}
+}
+
+// LoadSnapshot reads a snapshot.json file saved by a previous run.
+func LoadSnapshot(snapshotFile string) (utils.Snapshot, error) {
+	var snapshot utils.Snapshot
+
+	data, err := os.ReadFile(snapshotFile)
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("%s: %v", snapshotFile, err)
+	}
+	if snapshot.Version < 1 || snapshot.Version > utils.SnapshotVersion {
+		return snapshot, fmt.Errorf("%s: unsupported snapshot version %d", snapshotFile, snapshot.Version)
+	}
+	return snapshot, nil
+}
+
+// CompareSnapshots sorts the snapshots of two or more audits by date and
+// returns the evolution of their key metrics.
+func CompareSnapshots(snapshots []utils.Snapshot) utils.Trend {
+	sorted := append([]utils.Snapshot(nil), snapshots...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	var trend utils.Trend
+	cracked, hashes := true, true
+	for _, snapshot := range sorted {
+		trend.Dates = append(trend.Dates, snapshot.Date.Format("2006-01-02 15:04"))
+		cracked = cracked && !snapshot.Stats.HashOnly
+		hashes = hashes && snapshot.Stats.Hashes.IsHash
+	}
+	if len(sorted) < 2 {
+		return trend
+	}
+
+	for _, m := range trendMetrics {
+		if (m.cracked && !cracked) || (m.hashes && !hashes) {
+			continue
+		}
+		metric := utils.TrendMetric{Key: m.key, Group: m.group, HigherIsBetter: m.higherIsBetter}
+		for _, snapshot := range sorted {
+			metric.Values = append(metric.Values, m.value(snapshot.Stats))
+		}
+		metric.Delta = math.Round((metric.Last()-metric.Previous())*100) / 100
+		trend.Metrics = append(trend.Metrics, metric)
+	}
+	return trend
+}
@@ -0,0 +1,46 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"password-analyzer/utils"
+)
+
+// UnchangedSince matches the current accounts with those of a previous audit
+// of the same client, by `DOMAIN\username` or, for renamed accounts, by
+// domain and RID, and lists the accounts whose NT hash did not change: their
+// users never changed their password between both audits.
+func UnchangedSince(current, previous []utils.Account) utils.UnchangedStats {
+	byName := make(map[string]utils.Account, len(previous))
+	byRID := make(map[string]utils.Account, len(previous))
+	for _, account := range previous {
+		byName[strings.ToLower(account.Name())] = account
+		byRID[ridKey(account)] = account
+	}
+
+	var stats utils.UnchangedStats
+	for _, account := range current {
+		old, found := byName[strings.ToLower(account.Name())]
+		if !found {
+			old, found = byRID[ridKey(account)]
+		}
+		if !found {
+			continue
+		}
+
+		stats.Matched++
+		if old.NTHash == account.NTHash {
+			stats.Accounts = append(stats.Accounts, account.Name())
+			if account.Cracked {
+				stats.Cracked++
+			}
+		}
+	}
+	return stats
+}
+
+// ridKey identifies an account by its domain and RID.
+func ridKey(account utils.Account) string {
+	return fmt.Sprintf("%s\\%d", strings.ToLower(account.Domain), account.RID)
+}
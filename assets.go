@@ -0,0 +1,81 @@
+// Package assets embeds the default report templates, language files and
+// logos in the binary, so that PassTek runs from any directory. The files
+// keep their repository paths (lang/fr.json, export/template/template.html,
+// img/logo_sysdream.png, …) and can be overridden by the files of the same
+// path in the directory set with SetDir (-assets option).
+//
+// The package lives at the root of the module because go:embed cannot
+// reference files of parent directories.
+package assets
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+)
+
+//go:embed lang/*.json export/template img/logo_sysdream.png img/logo_passtek.png
+var embedded embed.FS
+
+// dir is the override directory, empty to only use the embedded files.
+var dir string
+
+// FS gives access to the assets, the files of the override directory taking
+// precedence over the embedded ones.
+var FS fs.FS = overlayFS{}
+
+// overlayFS looks a file up in the override directory, then in the embedded
+// files.
+type overlayFS struct{}
+
+func (overlayFS) Open(name string) (fs.File, error) {
+	if dir != "" {
+		if f, err := os.DirFS(dir).Open(name); err == nil {
+			return f, nil
+		}
+	}
+	return embedded.Open(name)
+}
+
+// ReadDir lists a directory of the override directory and of the embedded
+// files, so that overriding one file does not hide the others.
+func (overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(embedded, name)
+	if dir == "" {
+		return entries, err
+	}
+	overrides, overrideErr := fs.ReadDir(os.DirFS(dir), name)
+	if overrideErr != nil {
+		return entries, err
+	}
+	for _, entry := range overrides {
+		if !slices.ContainsFunc(entries, func(e fs.DirEntry) bool { return e.Name() == entry.Name() }) {
+			entries = append(entries, entry)
+		}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return entries, nil
+}
+
+// SetDir sets the directory whose files override the embedded assets. It
+// returns an error if path is not a directory.
+func SetDir(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	dir = path
+	return nil
+}
+
+// ReadFile returns the content of an asset, name being its slash-separated
+// path in the repository (e.g. "lang/en.json").
+func ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(FS, name)
+}
@@ -1,14 +1,17 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	assets "password-analyzer"
 	"password-analyzer/analysis"
 	"password-analyzer/export"
 	"password-analyzer/utils"
@@ -17,20 +20,40 @@ import (
 )
 
 func main() {
+	// Compare the snapshots of previous audits: PassTek diff a.json b.json
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		runDiff(os.Args[2:])
+		return
+	}
+
 	// -----------------------
 	// Command-line arguments
 	// -----------------------
 	passwordFile := flag.String("p", "", "Password file (one per line)")
-	outputTypes := flag.String("f", "all", "Output types (text, html, excel, screenshot, all)")
+	outputTypes := flag.String("f", "all", "Output types (text, html, pdf, excel, screenshot, json, csv, reset, docx, markdown, all). all writes every report except csv and reset, which list accounts (requires -H) and must be asked for explicitly")
 	lang := flag.String("l", "fr", "Output language (en,fr)")
 	outputDir := flag.String("o", "output", "Output directory")
 	hashFile := flag.String("H", "", "Hash file (username:rid:lmhash:nthash:::)")
-	logo := flag.String("L", "img/logo_sysdream.png", "Company logo file (png)")
+	potFile := flag.String("pot", "", "Potfile joined to the hash file by NT hash (hashcat potfile, John pot, hashcat --show --username)")
+	logo := flag.String("L", "img/logo_sysdream.png", "Company logo file (png), the default one being read from the assets")
 	clientLogo := flag.String("cL", "", "Client logo file (png)")
 	maskPasswords := flag.Bool("anon", false, "Anonymize passwords (show first 2 and last 2 characters)")
 	minCharOccurences := flag.Int("min", 5, "Minimum number of characters to be considered as an occurrence")
 	top := flag.Int("top", 5, "Top N entries to display in charts and tables")
+	includeDisabled := flag.Bool("disabled", false, "Include disabled accounts (secretsdump -user-status) in statistics")
+	maxAge := flag.Int("maxage", 365, "Password age, in days, above which a password is reported as old (secretsdump -pwd-last-set)")
+	classFile := flag.String("classes", "", "JSON file of account classification rules ({\"class\": [\"regex\", ...]}) overriding the built-in ones (requires -H)")
+	includeMachines := flag.Bool("machines", false, "Include machine accounts (ending with $) in statistics")
+	adminPatterns := flag.String("adminpatterns", strings.Join(analysis.DefaultAdminPatterns, ","), "Comma-separated names of the administration account of a user, {user} being the personal username")
+	previousFile := flag.String("prev", "", "Hash file of a previous audit, to list passwords unchanged since then (requires -H)")
+	compareFiles := flag.String("compare", "", "Comma-separated snapshot.json files of previous audits to compare with")
+	groupFile := flag.String("groups", "", "Group membership CSV (username,group) tagging privileged accounts (requires -H)")
+	weighted := flag.Bool("weighted", false, "Also weight password statistics by the number of accounts using each password (requires -H)")
+	templateDir := flag.String("template", "", "Directory of a custom HTML template set (template.html, style.css, cover.html, sections.html, partials) replacing the default files of the same name")
+	assetsDir := flag.String("assets", "", "Directory of files overriding the embedded assets, with the same layout (lang/, export/template/, img/)")
+	pdfBackend := flag.String("pdf", "chrome", "PDF backend (chrome: HTML report printed by Chrome, native: drawn in Go, no browser needed)")
 	flag.Parse()
+	started := time.Now()
 
 	fmt.Println(`
      ▗▄▄▖  ▗▄▖  ▗▄▄▖ ▗▄▄▖▗▄▄▄▖▗▄▄▄▖▗▖ ▗▖
@@ -64,9 +87,55 @@ func main() {
 	}
 	*outputDir = rel // cleaned safe relative path
 
-	if *passwordFile == "" {
+	if *passwordFile == "" && *potFile == "" && *hashFile == "" {
+		s.Errorf("Something went wrong")
+		log.Fatal("[!][main] Please specify an input file using -p, -pot or -H")
+	}
+	if *passwordFile != "" && *potFile != "" {
+		s.Errorf("Something went wrong")
+		log.Fatal("[!][main] -p and -pot cannot be used together")
+	}
+	if *potFile != "" && *hashFile == "" {
+		s.Errorf("Something went wrong")
+		log.Fatal("[!][main] -pot requires a hash file (-H) to join cracked passwords to accounts")
+	}
+	if *weighted && (*hashFile == "" || (*passwordFile == "" && *potFile == "")) {
+		s.Errorf("Something went wrong")
+		log.Fatal("[!][main] -weighted requires a hash file (-H) and cracked passwords (-p or -pot) to count accounts per password")
+	}
+	if *groupFile != "" && *hashFile == "" {
+		s.Errorf("Something went wrong")
+		log.Fatal("[!][main] -groups requires a hash file (-H) to tag accounts")
+	}
+	if *previousFile != "" && *hashFile == "" {
+		s.Errorf("Something went wrong")
+		log.Fatal("[!][main] -prev requires the current hash file (-H) to compare with")
+	}
+	if *classFile != "" && *hashFile == "" {
+		s.Errorf("Something went wrong")
+		log.Fatal("[!][main] -classes requires a hash file (-H) to classify accounts")
+	}
+	for _, output := range []string{"csv", "reset"} {
+		if slices.Contains(utils.SplitOutputTypes(*outputTypes), output) && *hashFile == "" {
+			s.Errorf("Something went wrong")
+			log.Fatalf("[!][main] %s output requires a hash file (-H) to list accounts", output)
+		}
+	}
+	if *pdfBackend != "chrome" && *pdfBackend != "native" {
 		s.Errorf("Something went wrong")
-		log.Fatal("[!][main] Please specify an input file using -p")
+		log.Fatalf("[!][main] Unknown PDF backend: %s (chrome, native)", *pdfBackend)
+	}
+	if *assetsDir != "" {
+		if err := assets.SetDir(*assetsDir); err != nil {
+			s.Errorf("Something went wrong")
+			log.Fatalf("[!][main][SetDir] Invalid assets directory: %v", err)
+		}
+	}
+	if *templateDir != "" {
+		if info, err := os.Stat(*templateDir); err != nil || !info.IsDir() {
+			s.Errorf("Something went wrong")
+			log.Fatalf("[!][main] Template directory not found: %s", *templateDir)
+		}
 	}
 	if *outputDir == "" {
 		s.Errorf("Something went wrong")
@@ -80,28 +149,174 @@ func main() {
 		log.Printf("[!][main] Cannot create %s: %v", *outputDir, err)
 	}
 
-	s.UpdateMessage("Analyzing passwords")
-	data, err := analysis.AnalyzePasswords(*passwordFile, *minCharOccurences)
-	if err != nil {
-		s.Errorf("Something went wrong")
-		log.Fatalf("[!][main][AnalyzePasswords] Error reading passwords: %v", err)
+	var data utils.Data
+	var accounts []utils.Account
+	var passwords []string
+	var orphans []string
+	var preWin2000 []string
+	var builtin utils.BuiltinStats
+	if *hashFile != "" {
+		s.UpdateMessage("Reading hashes")
+		accounts, err = analysis.ParsePwdump(*hashFile)
+		if err != nil {
+			s.Errorf("Something went wrong")
+			log.Fatalf("[!][main][ParsePwdump] Error reading hashes: %v", err)
+		}
+
+		// Classify accounts (machine, service, admin, user)
+		rules := analysis.DefaultClassRules
+		if *classFile != "" {
+			rules, err = analysis.LoadClassRules(*classFile)
+			if err != nil {
+				s.Errorf("Something went wrong")
+				log.Fatalf("[!][main][LoadClassRules] Error reading classification rules: %v", err)
+			}
+		}
+		err = analysis.Classify(accounts, rules)
+		if err != nil {
+			s.Errorf("Something went wrong")
+			log.Fatalf("[!][main][Classify] Error classifying accounts: %v", err)
+		}
+
+		// Computer accounts still using their pre-Windows 2000 default password,
+		// checked before machine and disabled accounts are left out
+		preWin2000 = analysis.PreWindows2000(accounts)
+		builtin = analysis.AnalyzeBuiltin(accounts)
 	}
-	data.Stats.Top = *top
 
-	if *hashFile != "" {
-		s.UpdateMessage("Analyzing hashes")
-		data.Stats.Hashes, err = analysis.AnalyzeHashes(*hashFile)
+	switch {
+	case *potFile != "":
+		s.UpdateMessage("Joining potfile to accounts")
+		cracked, err := analysis.LoadPotfile(*potFile)
 		if err != nil {
 			s.Errorf("Something went wrong")
-			log.Fatalf("[!][main][AnalyzeHashes] Error reading hashes: %v", err)
+			log.Fatalf("[!][main][LoadPotfile] Error reading potfile: %v", err)
 		}
-		data.Stats.Hashes.IsHash = true
+		analysis.JoinCracked(accounts, cracked)
+	case *passwordFile != "":
+		passwords, err = analysis.ReadPasswords(*passwordFile)
+		if err != nil {
+			s.Errorf("Something went wrong")
+			log.Fatalf("[!][main][ReadPasswords] Error reading passwords: %v", err)
+		}
+		if *hashFile != "" {
+			s.UpdateMessage("Attributing passwords to accounts")
+			orphans = analysis.AttributePasswords(accounts, passwords)
+			if len(orphans) > 0 {
+				fmt.Printf("\x1b[33m[WARNING]\x1b[37m %d cracked password(s) match no hash of the hash file (-H): check that both files come from the same extract.\n", len(orphans))
+			}
+		}
+	default:
+		// Hash-only mode: nothing cracked yet, report on the dump alone
+		data.Stats.HashOnly = true
+	}
 
-		// Detect accounts where password equals their username
-		data.Stats.Hashes.UserEqualHash, err = analysis.UsernameAsPass(*hashFile)
+	// Disabled accounts (secretsdump -user-status) are left out of the statistics unless -disabled
+	disabled, disabledCracked := 0, 0
+	for _, account := range accounts {
+		if account.Disabled() {
+			disabled++
+			if account.Cracked {
+				disabledCracked++
+			}
+		}
+	}
+	if disabled > 0 && !*includeDisabled {
+		accounts, passwords = analysis.ExcludeDisabled(accounts, passwords)
+	}
+
+	// Machine accounts have random passwords and are left out unless -machines
+	machines := 0
+	for _, account := range accounts {
+		if account.Machine() {
+			machines++
+		}
+	}
+	if machines > 0 && !*includeMachines {
+		accounts, passwords = analysis.ExcludeMachines(accounts, passwords)
+	}
+	if *potFile != "" {
+		passwords = analysis.CrackedPasswords(accounts)
+	}
+
+	if !data.Stats.HashOnly {
+		s.UpdateMessage("Analyzing passwords")
+		data, err = analysis.AnalyzePasswordList(passwords, *minCharOccurences)
+		if errors.Is(err, analysis.ErrTooFewPasswords) && *hashFile != "" {
+			// A single cracked password after -pot or the exclusions: the
+			// account findings (reuse, LM, built-in accounts, age) still hold
+			fmt.Printf("\x1b[33m[WARNING]\x1b[37m Fewer than 2 cracked passwords left: only hash statistics are reported.\n")
+			data.Stats.HashOnly = true
+		} else if err != nil {
+			s.Errorf("Something went wrong")
+			log.Fatalf("[!][main][AnalyzePasswordList] Error analyzing cracked passwords: %v", err)
+		}
+	}
+	analysis.AssignReuseGroups(accounts)
+
+	// Privileged group membership (-groups)
+	var privilegedAccounts []utils.Account
+	if *groupFile != "" {
+		s.UpdateMessage("Reading group membership")
+		groups, err := analysis.LoadGroups(*groupFile)
 		if err != nil {
 			s.Errorf("Something went wrong")
-			log.Printf("[!][main][UsersWithUsernameEqualHash] %v", err)
+			log.Fatalf("[!][main][LoadGroups] Error reading group file: %v", err)
+		}
+		var ambiguous []string
+		privilegedAccounts, ambiguous = analysis.TagGroups(accounts, groups)
+		if len(ambiguous) > 0 {
+			fmt.Printf("\x1b[33m[WARNING]\x1b[37m Bare names of the group file (-groups) match accounts of several domains and were ignored, use DOMAIN\\user: %s\n", strings.Join(ambiguous, ", "))
+		}
+		if len(privilegedAccounts) == 0 {
+			fmt.Println("\x1b[33m[WARNING]\x1b[37m No account of the group file (-groups) matches the hash file (-H).")
+		}
+	}
+	data.Stats.Clusters = analysis.ReuseClusters(accounts)
+	data.Stats.History = analysis.AnalyzeHistory(accounts)
+	data.Stats.Top = *top
+	data.Stats.Accounts = accounts
+	data.Stats.Orphans = orphans
+
+	if *weighted {
+		s.UpdateMessage("Weighting statistics by account")
+		data.Stats.Weighted = analysis.WeightByAccounts(accounts, data.Stats, *potFile != "")
+	}
+
+	if *hashFile != "" {
+		s.UpdateMessage("Analyzing hashes")
+		data.Stats.Hashes = analysis.ComputeHashStats(accounts)
+		data.Stats.Hashes.IsHash = true
+		data.Stats.Hashes.Disabled = disabled
+		data.Stats.Hashes.DisabledCracked = disabledCracked
+		data.Stats.Hashes.DisabledIncluded = *includeDisabled
+		data.Stats.Hashes.Machines = machines
+		data.Stats.Hashes.MachinesIncluded = *includeMachines
+
+		// Detect accounts where password equals their username
+		data.Stats.Hashes.UserEqualHash = analysis.UsersAsPassword(accounts)
+
+		// Password age (secretsdump -pwd-last-set)
+		data.Stats.Age = analysis.AnalyzeAge(accounts, time.Now(), *maxAge)
+		data.Stats.PreWin2000 = preWin2000
+		data.Stats.Builtin = builtin
+
+		// Personal and administration accounts of the same user
+		data.Stats.AdminLinks = analysis.LinkAdminAccounts(accounts, strings.Split(*adminPatterns, ","))
+
+		// Passwords unchanged since a previous audit
+		if *previousFile != "" {
+			s.UpdateMessage("Comparing with the previous audit")
+			previous, err := analysis.ParsePwdump(*previousFile)
+			if err != nil {
+				s.Errorf("Something went wrong")
+				log.Fatalf("[!][main][ParsePwdump] Error reading previous hashes: %v", err)
+			}
+			data.Stats.Unchanged = analysis.UnchangedSince(accounts, previous)
+			data.Stats.Unchanged.Enabled = true
+		}
+		if !data.Stats.HashOnly {
+			data.Stats.Builtin.RIDRanges = analysis.RIDRanges(accounts)
 		}
 
 		// Sanity check: the hash file must not contain fewer entries than the password list
@@ -118,29 +333,83 @@ func main() {
 		data.Stats.Hashes.IsHash = false
 	}
 
+	if *groupFile != "" {
+		s.UpdateMessage("Analyzing privileged accounts")
+		privileged, err := analysis.SubsetStats(data.Stats, privilegedAccounts, *minCharOccurences)
+		if err != nil {
+			s.Errorf("Something went wrong")
+			log.Fatalf("[!][main][SubsetStats] Error analyzing privileged accounts: %v", err)
+		}
+		analysis.StatsRisk(*lang, &privileged)
+		data.Stats.Privileged = &privileged
+	}
+
+	if *hashFile != "" {
+		s.UpdateMessage("Analyzing account classes")
+		data.Stats.Classes, err = analysis.ClassBreakdown(data.Stats, accounts, *minCharOccurences)
+		if err != nil {
+			s.Errorf("Something went wrong")
+			log.Fatalf("[!][main][ClassBreakdown] Error analyzing account classes: %v", err)
+		}
+		for i := range data.Stats.Classes {
+			analysis.StatsRisk(*lang, &data.Stats.Classes[i].Stats)
+		}
+
+		s.UpdateMessage("Analyzing domains")
+		data.Stats.Domains, err = analysis.DomainBreakdown(data.Stats, accounts, *minCharOccurences)
+		if err != nil {
+			s.Errorf("Something went wrong")
+			log.Fatalf("[!][main][DomainBreakdown] Error analyzing domains: %v", err)
+		}
+		for i := range data.Stats.Domains {
+			analysis.StatsRisk(*lang, &data.Stats.Domains[i].Stats)
+		}
+		data.Stats.CrossDomain = analysis.CrossDomainReuse(accounts)
+	}
+
 	s.UpdateMessage("Risk evaluation")
 	// Evaluate risk and global percent if hash file or not
-	if data.Stats.Hashes.IsHash {
-		data.Stats.Risk, data.Stats.GlobalPercent = analysis.EvaluateRisk(
-			*lang,
-			utils.Percent(data.Stats.Hashes.ReusedNTLMHashes, data.Stats.Hashes.TotalNTLMHashes),
-			utils.Percent(data.Stats.Complexity[1]+data.Stats.Complexity[2]+data.Stats.Complexity[3], data.Stats.CrackedCount),
-			utils.Percent(utils.SumLengthRange(data.Stats.Lengths, 0, 10), data.Stats.CrackedCount),
-			utils.Percent(data.Stats.CrackedCount, data.Stats.Hashes.TotalNTLMHashes),
-		)
-	} else {
-		data.Stats.Risk, data.Stats.GlobalPercent = analysis.EvaluateRisk(
-			*lang,
-			utils.Percent(data.Stats.Hashes.ReusedNTLMHashes, data.Stats.Hashes.TotalNTLMHashes),
-			utils.Percent(data.Stats.Complexity[1]+data.Stats.Complexity[2]+data.Stats.Complexity[3], data.Stats.CrackedCount),
-			utils.Percent(utils.SumLengthRange(data.Stats.Lengths, 0, 10), data.Stats.CrackedCount),
-		)
-	}
+	analysis.StatsRisk(*lang, &data.Stats)
+
+	// Accounts affected by each finding, for the remediation exports
+	findings := analysis.AccountFindings(data.Stats, *top)
 
 	// Apply masking if requested
 	if *maskPasswords {
 		s.UpdateMessage("Masking passwords")
 		utils.MaskStats(&data.Stats)
+		utils.MaskFindings(findings)
+	}
+
+	// Describe the run for the snapshot and the JSON report
+	data.Run = utils.RunMetadata{Date: started, Lang: *lang, Options: make(map[string]string)}
+	flag.VisitAll(func(f *flag.Flag) {
+		data.Run.Options[f.Name] = f.Value.String()
+	})
+
+	snapshot := analysis.NewSnapshot(data.Run.Date, data.Stats)
+
+	// Compare with the snapshots of previous audits, loaded before this run's
+	// snapshot is saved since -compare may point to the same file
+	if *compareFiles != "" {
+		s.UpdateMessage("Comparing with previous audits")
+		snapshots := []utils.Snapshot{snapshot}
+		for _, file := range strings.Split(*compareFiles, ",") {
+			previous, err := analysis.LoadSnapshot(strings.TrimSpace(file))
+			if err != nil {
+				s.Errorf("Something went wrong")
+				log.Fatalf("[!][main][LoadSnapshot] Error reading snapshot: %v", err)
+			}
+			snapshots = append(snapshots, previous)
+		}
+		trend := analysis.CompareSnapshots(snapshots)
+		data.Stats.Trend = &trend
+	}
+
+	// Save a snapshot of this run, to be compared with the next audits
+	if err := export.ToSnapshot(snapshot, *outputDir); err != nil {
+		s.Errorf("Something went wrong")
+		log.Fatalf("[!][main][ToSnapshot] Error saving snapshot: %v", err)
 	}
 
 	// Note: HTML escaping is now handled directly in the language templates via the
@@ -163,6 +432,13 @@ func main() {
 	// Load logos (after loading labels) else hidden img
 	if *logo == "" {
 		data.Labels.Html.IsLogo = "hidden"
+	} else if *logo == flag.Lookup("L").DefValue {
+		// Default logo: an asset, which only -assets overrides
+		data.Labels.Html.Logo64, err = utils.AssetToBase64(*logo)
+		if err != nil {
+			s.Errorf("Something went wrong")
+			log.Fatalf("[!][main][AssetToBase64] Error loading logo: %v", err)
+		}
 	} else {
 		data.Labels.Html.Logo64, err = utils.ImageToBase64(*logo)
 		if err != nil {
@@ -189,7 +465,7 @@ func main() {
 			s.Success("[+] Saved text report to " + *outputDir + "/report.txt")
 		case "html":
 			s.UpdateMessage("Generating HTML report")
-			export.ToHtml(data.Stats, *outputDir, data)
+			export.ToHtml(data.Stats, *outputDir, data, *templateDir)
 			s.Success("[+] Saved HTML report to " + *outputDir + "/report.html")
 		case "excel":
 			s.UpdateMessage("Generating Excel report")
@@ -203,11 +479,57 @@ func main() {
 				log.Fatalf("[!][main] Cannot create %s: %v", *outputDir+"/screenshots", err)
 			}
 			s.UpdateMessage("Generating screenshots")
-			export.ToPNG(data.Stats, data.Labels, *outputDir)
+			if err := export.ToPNG(data, *outputDir); err != nil {
+				s.Errorf("Something went wrong")
+				log.Fatalf("[!][main][ToPNG] Error writing screenshots: %v", err)
+			}
 			s.Success("[+] Saved screenshots to " + *outputDir + "/screenshots")
+		case "json":
+			s.UpdateMessage("Generating JSON report")
+			if err := export.ToJSON(data, *outputDir); err != nil {
+				s.Errorf("Something went wrong")
+				log.Fatalf("[!][main][ToJSON] Error writing JSON report: %v", err)
+			}
+			s.Success("[+] Saved JSON report to " + *outputDir + "/report.json")
+		case "csv":
+			s.UpdateMessage("Generating CSV files")
+			if err := export.ToCSV(findings, *outputDir, data.Labels); err != nil {
+				s.Errorf("Something went wrong")
+				log.Fatalf("[!][main][ToCSV] Error writing CSV files: %v", err)
+			}
+			s.Success("[+] Saved CSV files to " + *outputDir + "/csv")
+		case "reset":
+			s.UpdateMessage("Generating password reset script")
+			if err := export.ToResetScript(findings, *outputDir, data.Labels); err != nil {
+				s.Errorf("Something went wrong")
+				log.Fatalf("[!][main][ToResetScript] Error writing reset script: %v", err)
+			}
+			s.Success("[+] Saved password reset script to " + *outputDir + "/reset-passwords.ps1")
+		case "docx":
+			s.UpdateMessage("Generating Word report")
+			if err := export.ToDocx(data, *outputDir); err != nil {
+				s.Errorf("Something went wrong")
+				log.Fatalf("[!][main][ToDocx] Error writing Word report: %v", err)
+			}
+			s.Success("[+] Saved Word report to " + *outputDir + "/report.docx")
+		case "markdown":
+			s.UpdateMessage("Generating Markdown report")
+			if err := export.ToMarkdown(data, *outputDir, *top); err != nil {
+				s.Errorf("Something went wrong")
+				log.Fatalf("[!][main][ToMarkdown] Error writing Markdown report: %v", err)
+			}
+			s.Success("[+] Saved Markdown report to " + *outputDir + "/report.md")
 		case "pdf":
 			s.UpdateMessage("Generating PDF report")
-			export.ToHtml(data.Stats, *outputDir, data)
+			if *pdfBackend == "native" {
+				if err := export.ToNativePDF(data, *outputDir); err != nil {
+					s.Errorf("Something went wrong")
+					log.Fatalf("[!][main][ToNativePDF] Error writing PDF report: %v", err)
+				}
+				s.Success("[+] Saved PDF report to " + *outputDir + "/report.pdf")
+				break
+			}
+			export.ToHtml(data.Stats, *outputDir, data, *templateDir)
 			export.ToPDF(*outputDir)
 			s.Success("[+] Saved PDF report to " + *outputDir + "/report.pdf")
 			// Remove report.html file once PDF is generated
@@ -229,11 +551,18 @@ func main() {
 			s.Success("[+] Saved text report to " + *outputDir + "/report.txt")
 			s.Start("Generating HTML report")
 			time.Sleep(2 * time.Second)
-			export.ToHtml(data.Stats, *outputDir, data)
+			export.ToHtml(data.Stats, *outputDir, data, *templateDir)
 			s.Success("[+] Saved HTML report to " + *outputDir + "/report.html")
 			s.Start("Generating PDF report")
 			time.Sleep(2 * time.Second)
-			export.ToPDF(*outputDir)
+			if *pdfBackend == "native" {
+				if err := export.ToNativePDF(data, *outputDir); err != nil {
+					s.Errorf("Something went wrong")
+					log.Fatalf("[!][main][ToNativePDF] Error writing PDF report: %v", err)
+				}
+			} else {
+				export.ToPDF(*outputDir)
+			}
 			s.Success("[+] Saved PDF report to " + *outputDir + "/report.pdf")
 			s.Start("Generating Excel report")
 			time.Sleep(2 * time.Second)
@@ -241,8 +570,29 @@ func main() {
 			s.Success("[+] Saved Excel report to " + *outputDir + "/report.xlsx")
 			s.Start("Generating screenshots")
 			time.Sleep(2 * time.Second)
-			export.ToPNG(data.Stats, data.Labels, *outputDir)
+			if err := export.ToPNG(data, *outputDir); err != nil {
+				s.Errorf("Something went wrong")
+				log.Fatalf("[!][main][ToPNG] Error writing screenshots: %v", err)
+			}
 			s.Success("[+] Saved screenshots to " + *outputDir + "/screenshots")
+			s.Start("Generating JSON report")
+			if err := export.ToJSON(data, *outputDir); err != nil {
+				s.Errorf("Something went wrong")
+				log.Fatalf("[!][main][ToJSON] Error writing JSON report: %v", err)
+			}
+			s.Success("[+] Saved JSON report to " + *outputDir + "/report.json")
+			s.Start("Generating Word report")
+			if err := export.ToDocx(data, *outputDir); err != nil {
+				s.Errorf("Something went wrong")
+				log.Fatalf("[!][main][ToDocx] Error writing Word report: %v", err)
+			}
+			s.Success("[+] Saved Word report to " + *outputDir + "/report.docx")
+			s.Start("Generating Markdown report")
+			if err := export.ToMarkdown(data, *outputDir, *top); err != nil {
+				s.Errorf("Something went wrong")
+				log.Fatalf("[!][main][ToMarkdown] Error writing Markdown report: %v", err)
+			}
+			s.Success("[+] Saved Markdown report to " + *outputDir + "/report.md")
 		default:
 			s.Errorf("Something went wrong")
 			log.Fatalf("[!][main] Unknown output type: %s\n", output)
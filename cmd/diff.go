@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	assets "password-analyzer"
+	"password-analyzer/analysis"
+	"password-analyzer/export"
+	"password-analyzer/utils"
+)
+
+// runDiff implements the diff mode: it compares the snapshot.json files of
+// two or more audits and prints the evolution of their key metrics.
+func runDiff(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	lang := flags.String("l", "fr", "Output language (en,fr)")
+	assetsDir := flags.String("assets", "", "Directory of files overriding the embedded assets (lang/)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: PassTek diff [-l lang] [-assets dir] snapshot.json snapshot.json [snapshot.json ...]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		log.Fatal("[!][diff] Please specify at least two snapshot files")
+	}
+
+	if *assetsDir != "" {
+		if err := assets.SetDir(*assetsDir); err != nil {
+			log.Fatalf("[!][diff][SetDir] Invalid assets directory: %v", err)
+		}
+	}
+
+	var snapshots []utils.Snapshot
+	for _, file := range flags.Args() {
+		snapshot, err := analysis.LoadSnapshot(file)
+		if err != nil {
+			log.Fatalf("[!][diff][LoadSnapshot] Error reading snapshot: %v", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	trend := analysis.CompareSnapshots(snapshots)
+
+	// Labels are templated with the latest audit
+	defer os.Remove(fmt.Sprintf("tmp-%s.json", *lang))
+	latest := snapshots[0]
+	for _, snapshot := range snapshots[1:] {
+		if snapshot.Date.After(latest.Date) {
+			latest = snapshot
+		}
+	}
+	data := utils.Data{Stats: latest.Stats}
+	data.Stats.Trend = &trend
+	if err := utils.InsertStats(*lang, data); err != nil {
+		log.Fatalf("[!][diff][InsertStats] Error templating json file: %v", err)
+	}
+	labels, err := utils.LoadLabels(*lang)
+	if err != nil {
+		log.Fatalf("[!][diff][LoadLabels] Error loading language file: %v", err)
+	}
+
+	export.WriteTrend(os.Stdout, trend, labels)
+}
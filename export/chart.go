@@ -0,0 +1,387 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+	"golang.org/x/text/unicode/norm"
+)
+
+// chartPalette holds the slice and line colours, used in turn.
+var chartPalette = []color.RGBA{
+	{0x23, 0x83, 0xc6, 0xff},
+	{0x67, 0xb7, 0xdc, 0xff},
+	{0x84, 0x5e, 0xc2, 0xff},
+	{0xd6, 0x5d, 0xb1, 0xff},
+	{0xff, 0x6f, 0x91, 0xff},
+	{0xff, 0x96, 0x71, 0xff},
+	{0xf9, 0xc7, 0x4f, 0xff},
+}
+
+// Colours of the risk gauge bands, the same as the HTML gauge, and of the
+// chart texts and axes.
+var (
+	gaugeBands = []color.RGBA{
+		{0x64, 0xcf, 0x73, 0xff},
+		{0xff, 0xe0, 0x66, 0xff},
+		{0xff, 0xae, 0x42, 0xff},
+		{0xfa, 0x62, 0x62, 0xff},
+	}
+	gaugeHand = color.RGBA{0x24, 0x77, 0xaf, 0xff}
+	chartInk  = color.RGBA{0x2c, 0x3e, 0x50, 0xff}
+	chartGrid = color.RGBA{0xdd, 0xe3, 0xea, 0xff}
+)
+
+// Layout of the charts, in pixels. Texts are sized for the 7x13 bitmap font
+// of the PNG images.
+const (
+	chartCharWidth = 7
+	chartTextSize  = 13
+	pieRadius      = 120
+	pieWidth       = 660
+	pieLegendX     = 2*pieRadius + 60
+	legendRow      = 26
+)
+
+// chartAnchor is the horizontal alignment of a text on its position.
+type chartAnchor int
+
+const (
+	anchorStart chartAnchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// chartPoint is a point of a chart, y going down.
+type chartPoint struct {
+	X, Y float64
+}
+
+// chartShape is a filled polygon, a line (Width > 0) or a text drawn with its
+// baseline at Points[0] (Text set).
+type chartShape struct {
+	Points []chartPoint
+	Width  float64
+	Colour color.RGBA
+	Text   string
+	Size   float64
+	Bold   bool
+	Anchor chartAnchor
+}
+
+// chartScene is a chart laid out as shapes, drawn the same way in SVG, PNG
+// and PDF so that charts are identical in every output.
+type chartScene struct {
+	Width, Height float64
+	Shapes        []chartShape
+}
+
+// polygon adds a filled polygon.
+func (s *chartScene) polygon(c color.RGBA, points ...chartPoint) {
+	s.Shapes = append(s.Shapes, chartShape{Points: points, Colour: c})
+}
+
+// rect adds a filled rectangle, (x, y) being its top left corner.
+func (s *chartScene) rect(x, y, width, height float64, c color.RGBA) {
+	s.polygon(c, chartPoint{x, y}, chartPoint{x + width, y}, chartPoint{x + width, y + height}, chartPoint{x, y + height})
+}
+
+// line adds a line through points.
+func (s *chartScene) line(width float64, c color.RGBA, points ...chartPoint) {
+	s.Shapes = append(s.Shapes, chartShape{Points: points, Width: width, Colour: c})
+}
+
+// text adds a text with its baseline at (x, y).
+func (s *chartScene) text(x, y float64, text string, size float64, bold bool, anchor chartAnchor, c color.RGBA) {
+	s.Shapes = append(s.Shapes, chartShape{Points: []chartPoint{{x, y}}, Text: text, Size: size, Bold: bold, Anchor: anchor, Colour: c})
+}
+
+// scene lays out c according to its kind.
+func (c chart) scene() chartScene {
+	switch c.Kind {
+	case chartGauge:
+		return gaugeScene(c)
+	case chartLine:
+		return lineScene(c)
+	}
+	return pieScene(c)
+}
+
+// pieScene draws c as a pie chart, starting at the top and going clockwise,
+// with a legend giving the value and share of each slice.
+func pieScene(c chart) chartScene {
+	height := max(2*pieRadius+40, 40+len(c.Entries)*legendRow)
+	s := chartScene{Width: pieWidth, Height: float64(height)}
+	cx, cy := 20.0+pieRadius, float64(height)/2
+
+	var total float64
+	for _, e := range c.Entries {
+		total += e.Value
+	}
+	angle := 90.0
+	legendY := float64(height-len(c.Entries)*legendRow) / 2
+	for i, e := range c.Entries {
+		colour := chartPalette[i%len(chartPalette)]
+		share := 0.0
+		if total > 0 && e.Value > 0 {
+			share = e.Value / total * 100
+			sweep := share * 3.6
+			if sweep >= 359.99 {
+				s.polygon(colour, arcPoints(cx, cy, pieRadius, 0, 360)...)
+			} else {
+				s.polygon(colour, append([]chartPoint{{cx, cy}}, arcPoints(cx, cy, pieRadius, angle, angle-sweep)...)...)
+			}
+			angle -= sweep
+		}
+
+		y := legendY + float64(i*legendRow)
+		s.rect(pieLegendX, y+4, 14, 14, colour)
+		label := fmt.Sprintf("%s : %g (%.1f%%)", e.Label, e.Value, share)
+		s.text(pieLegendX+22, y+16, truncateLabel(label, (pieWidth-pieLegendX-22)/chartCharWidth), chartTextSize, false, anchorStart, chartInk)
+	}
+	return s
+}
+
+// gaugeScene draws the risk gauge: four bands from low to critical risk, a
+// hand on the score and the risk level below, as in the HTML summary.
+func gaugeScene(c chart) chartScene {
+	const (
+		width, height = 420.0, 260.0
+		cx, cy        = width / 2, 200.0
+		outer, inner  = 180.0, 148.0
+	)
+	s := chartScene{Width: width, Height: height}
+	score, risk := 0.0, ""
+	if len(c.Entries) > 0 {
+		score, risk = min(max(c.Entries[0].Value, 0), 100), c.Entries[0].Label
+	}
+
+	for i, colour := range gaugeBands {
+		from, to := 180-float64(i)*45, 180-float64(i+1)*45
+		band := append(arcPoints(cx, cy, outer, from, to), arcPoints(cx, cy, inner, to, from)...)
+		s.polygon(colour, band...)
+	}
+
+	// Hand, from 40% of the radius to the bands
+	angle := (180 - score*1.8) * math.Pi / 180
+	dx, dy := math.Cos(angle), -math.Sin(angle)
+	base := chartPoint{cx + dx*outer*0.4, cy + dy*outer*0.4}
+	s.polygon(gaugeHand,
+		chartPoint{base.X - dy*4, base.Y + dx*4},
+		chartPoint{cx + dx*outer, cy + dy*outer},
+		chartPoint{base.X + dy*4, base.Y - dx*4},
+	)
+
+	s.text(cx, cy+45, risk, 28, true, anchorMiddle, gaugeColour(score))
+	return s
+}
+
+// gaugeColour returns the colour of the gauge band of score, the thresholds
+// being those of analysis.EvaluateRisk.
+func gaugeColour(score float64) color.RGBA {
+	return gaugeBands[min(int(max(score, 0)/25), len(gaugeBands)-1)]
+}
+
+// lineScene draws c as a line chart, one point per category, with a legend
+// on the right.
+func lineScene(c chart) chartScene {
+	const (
+		width, height = 720.0, 320.0
+		left, right   = 80.0, 440.0
+		top, bottom   = 20.0, 270.0
+		legendX       = 480.0
+		gridLines     = 5
+	)
+	s := chartScene{Width: width, Height: height}
+
+	largest := 0.0
+	for _, series := range c.Series {
+		for _, value := range series.Values {
+			largest = max(largest, value)
+		}
+	}
+	// Round the axis up to a multiple of 10
+	axis := math.Max(10, math.Ceil(largest/10)*10)
+
+	for i := 0; i <= gridLines; i++ {
+		y := bottom - float64(i)*(bottom-top)/gridLines
+		s.line(1, chartGrid, chartPoint{left, y}, chartPoint{right, y})
+		s.text(left-8, y+4, fmt.Sprintf("%g", axis*float64(i)/gridLines), 11, false, anchorEnd, chartInk)
+	}
+
+	x := func(i int) float64 {
+		if len(c.Categories) < 2 {
+			return (left + right) / 2
+		}
+		return left + float64(i)*(right-left)/float64(len(c.Categories)-1)
+	}
+	for i, category := range c.Categories {
+		s.text(x(i), bottom+20, category, 11, false, anchorMiddle, chartInk)
+	}
+
+	for i, series := range c.Series {
+		colour := chartPalette[i%len(chartPalette)]
+		var points []chartPoint
+		for j, value := range series.Values {
+			points = append(points, chartPoint{x(j), bottom - value/axis*(bottom-top)})
+		}
+		if len(points) > 1 {
+			s.line(3, colour, points...)
+		}
+		for _, p := range points {
+			s.rect(p.X-4, p.Y-4, 8, 8, colour)
+		}
+
+		y := top + float64(i*legendRow)
+		s.rect(legendX, y, 14, 14, colour)
+		s.text(legendX+22, y+12, truncateLabel(series.Label, int(width-legendX-22)/chartCharWidth), chartTextSize, false, anchorStart, chartInk)
+	}
+	return s
+}
+
+// arcPoints returns the points of an arc of a circle from one angle to the
+// other, in degrees counter-clockwise from the right.
+func arcPoints(cx, cy, radius, from, to float64) []chartPoint {
+	steps := int(math.Ceil(math.Abs(to-from)/2)) + 1
+	points := make([]chartPoint, steps)
+	for i := range points {
+		angle := (from + (to-from)*float64(i)/float64(steps-1)) * math.Pi / 180
+		points[i] = chartPoint{cx + radius*math.Cos(angle), cy - radius*math.Sin(angle)}
+	}
+	return points
+}
+
+// chartSVG returns c as an SVG image.
+func chartSVG(c chart) string {
+	s := c.scene()
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]g" height="%[2]g" viewBox="0 0 %[1]g %[2]g" font-family="Helvetica, Arial, sans-serif">`, s.Width, s.Height)
+	b.WriteString(`<rect width="100%" height="100%" fill="#ffffff"/>`)
+	for _, shape := range s.Shapes {
+		colour := fmt.Sprintf("#%02x%02x%02x", shape.Colour.R, shape.Colour.G, shape.Colour.B)
+		switch {
+		case shape.Text != "":
+			anchor := [...]string{"start", "middle", "end"}[shape.Anchor]
+			weight := ""
+			if shape.Bold {
+				weight = ` font-weight="bold"`
+			}
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="%g"%s text-anchor="%s" fill="%s">%s</text>`, shape.Points[0].X, shape.Points[0].Y, shape.Size, weight, anchor, colour, xmlEscape(shape.Text))
+		case shape.Width > 0:
+			fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%g" stroke-linejoin="round"/>`, svgPoints(shape.Points), colour, shape.Width)
+		default:
+			fmt.Fprintf(&b, `<polygon points="%s" fill="%s"/>`, svgPoints(shape.Points), colour)
+		}
+	}
+	b.WriteString("</svg>")
+	return b.String()
+}
+
+// svgPoints formats points for the points attribute of SVG shapes.
+func svgPoints(points []chartPoint) string {
+	coordinates := make([]string, len(points))
+	for i, p := range points {
+		coordinates[i] = fmt.Sprintf("%.1f,%.1f", p.X, p.Y)
+	}
+	return strings.Join(coordinates, " ")
+}
+
+// chartPNG rasterises c and returns the PNG image.
+func chartPNG(c chart) ([]byte, error) {
+	s := c.scene()
+	img := image.NewRGBA(image.Rect(0, 0, int(math.Ceil(s.Width)), int(math.Ceil(s.Height))))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for _, shape := range s.Shapes {
+		switch {
+		case shape.Text != "":
+			x := shape.Points[0].X
+			width := float64(utf8.RuneCountInString(stripAccents(shape.Text)) * chartCharWidth)
+			switch shape.Anchor {
+			case anchorMiddle:
+				x -= width / 2
+			case anchorEnd:
+				x -= width
+			}
+			drawText(img, int(x), int(shape.Points[0].Y), shape.Text, shape.Colour)
+		case shape.Width > 0:
+			// One quadrilateral per segment
+			for i := 1; i < len(shape.Points); i++ {
+				a, b := shape.Points[i-1], shape.Points[i]
+				length := math.Hypot(b.X-a.X, b.Y-a.Y)
+				if length == 0 {
+					continue
+				}
+				nx, ny := -(b.Y-a.Y)/length*shape.Width/2, (b.X-a.X)/length*shape.Width/2
+				fillPolygon(img, shape.Colour, []chartPoint{{a.X + nx, a.Y + ny}, {b.X + nx, b.Y + ny}, {b.X - nx, b.Y - ny}, {a.X - nx, a.Y - ny}})
+			}
+		default:
+			fillPolygon(img, shape.Colour, shape.Points)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fillPolygon fills an anti-aliased polygon on img.
+func fillPolygon(img *image.RGBA, c color.RGBA, points []chartPoint) {
+	if len(points) < 3 {
+		return
+	}
+	bounds := img.Bounds()
+	r := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	r.DrawOp = draw.Over
+	r.MoveTo(float32(points[0].X), float32(points[0].Y))
+	for _, p := range points[1:] {
+		r.LineTo(float32(p.X), float32(p.Y))
+	}
+	r.ClosePath()
+	r.Draw(img, bounds, image.NewUniform(c), image.Point{})
+}
+
+// drawText writes s with its baseline at (x, y). The bitmap font only has
+// ASCII glyphs, so accents are dropped first.
+func drawText(img draw.Image, x, y int, s string, c color.Color) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(stripAccents(s))
+}
+
+// stripAccents returns s without its diacritics, e.g. "é" becomes "e".
+func stripAccents(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, norm.NFD.String(s))
+}
+
+// truncateLabel cuts s to n characters.
+func truncateLabel(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-2]) + ".."
+}
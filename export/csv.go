@@ -0,0 +1,84 @@
+package export
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"password-analyzer/utils"
+)
+
+// csvFiles names the file written for each finding by ToCSV.
+var csvFiles = map[string]string{
+	utils.FindingUserAsPassword: "username-as-password.csv",
+	utils.FindingLM:             "lm-stored.csv",
+	utils.FindingEmpty:          "empty-password.csv",
+	utils.FindingReused:         "reused.csv",
+	utils.FindingCracked:        "cracked.csv",
+	utils.FindingTopTokens:      "top-keywords.csv",
+}
+
+// ToCSV writes one CSV file per finding inside `outputDir/csv`, listing the
+// affected accounts for helpdesks: domain, username, RID and password, the
+// latter masked when -anon is set. Files are written even when no account
+// is affected, so that every run produces the same set.
+func ToCSV(findings []utils.Finding, outputDir string, labels utils.Labels) error {
+	dir := filepath.Join(outputDir, "csv")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, finding := range findings {
+		if err := writeFindingCSV(filepath.Join(dir, csvFiles[finding.Key]), finding, labels); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFindingCSV writes the accounts of finding to path, with a last column
+// for the details of findings that have some.
+func writeFindingCSV(path string, finding utils.Finding, labels utils.Labels) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	header := []string{labels.Accounts.Domain, labels.Accounts.Username, labels.Accounts.RID, labels.Accounts.Password}
+	if finding.Details != nil {
+		header = append(header, labels.Occurrences.A1)
+	}
+	if err := w.Write(csvRow(header)); err != nil {
+		return err
+	}
+	for i, account := range finding.Accounts {
+		row := []string{account.Domain, account.Username, strconv.Itoa(account.RID), account.Password}
+		if finding.Details != nil {
+			row = append(row, finding.Details[i])
+		}
+		if err := w.Write(csvRow(row)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// csvRow neutralises the cells that a spreadsheet would evaluate as a
+// formula (=, +, -, @, tab or carriage return first) by prefixing them with
+// a quote: passwords and usernames are chosen by the audited users and must
+// not run when the helpdesk opens the file.
+func csvRow(cells []string) []string {
+	row := make([]string, len(cells))
+	for i, cell := range cells {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			cell = "'" + cell
+		}
+		row[i] = cell
+	}
+	return row
+}
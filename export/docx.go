@@ -0,0 +1,338 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image"
+	_ "image/png" // Decoding of the logo and chart sizes
+	"os"
+	"strings"
+
+	"password-analyzer/utils"
+)
+
+// Word measures: A4 page and margins in twentieths of a point, images in
+// English Metric Units.
+const (
+	docxTextWidth  = 9638    // A4 width (11906) minus 2 cm margins
+	emuPerPixel    = 9525    // At 96 dpi
+	docxImageMax   = 6120000 // Text width, in EMU
+	docxLogoHeight = 540000  // 1.5 cm
+)
+
+// docxContentTypes, docxRels and docxStyles are the static parts of the
+// document. Heading1 and Heading2 reuse the ids of the Word built-in styles
+// so that the navigation pane and tables of contents pick them up.
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Default Extension="png" ContentType="image/png"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+</Types>`
+
+const docxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+const docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:docDefaults>
+<w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:cs="Calibri"/><w:sz w:val="22"/><w:color w:val="2C3E50"/></w:rPr></w:rPrDefault>
+<w:pPrDefault><w:pPr><w:spacing w:after="120" w:line="276" w:lineRule="auto"/></w:pPr></w:pPrDefault>
+</w:docDefaults>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>
+<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:jc w:val="center"/><w:spacing w:before="2400" w:after="240"/></w:pPr><w:rPr><w:b/><w:color w:val="2383C6"/><w:sz w:val="56"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:keepNext/><w:spacing w:before="360" w:after="120"/><w:pBdr><w:bottom w:val="single" w:sz="8" w:space="4" w:color="2477AF"/></w:pBdr><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:color w:val="2383C6"/><w:sz w:val="32"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:keepNext/><w:spacing w:before="240" w:after="80"/><w:outlineLvl w:val="1"/></w:pPr><w:rPr><w:b/><w:color w:val="2477AF"/><w:sz w:val="26"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="ListBullet"><w:name w:val="List Bullet"/><w:basedOn w:val="Normal"/><w:pPr><w:ind w:left="360" w:hanging="360"/></w:pPr></w:style>
+<w:style w:type="table" w:styleId="PassTekTable"><w:name w:val="PassTek Table"/><w:tblPr><w:tblBorders><w:top w:val="single" w:sz="4" w:color="BFC9D4"/><w:left w:val="single" w:sz="4" w:color="BFC9D4"/><w:bottom w:val="single" w:sz="4" w:color="BFC9D4"/><w:right w:val="single" w:sz="4" w:color="BFC9D4"/><w:insideH w:val="single" w:sz="4" w:color="BFC9D4"/><w:insideV w:val="single" w:sz="4" w:color="BFC9D4"/></w:tblBorders><w:tblCellMar><w:left w:w="80" w:type="dxa"/><w:right w:w="80" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>
+</w:styles>`
+
+// docx builds the body of a Word document and collects the images it embeds.
+type docx struct {
+	body   strings.Builder
+	images [][]byte
+}
+
+// ToDocx writes a `report.docx` file inside outputDir with the sections of
+// the HTML report: cover with the logos, localised texts, tables and charts.
+// The document is assembled by hand (Office Open XML in a zip archive), so
+// no office suite is needed.
+func ToDocx(data utils.Data, outputDir string) error {
+	d := &docx{}
+	labels := data.Labels
+
+	// Cover
+	var logos [][]byte
+	for _, logo := range []struct{ hidden, base64 string }{
+		{labels.Html.IsLogo, labels.Html.Logo64},
+		{labels.Html.IsClientLogo, labels.Html.ClientLogo64},
+	} {
+		if logo.hidden == "hidden" || logo.base64 == "" {
+			continue
+		}
+		img, err := base64.StdEncoding.DecodeString(logo.base64)
+		if err != nil {
+			return err
+		}
+		logos = append(logos, img)
+	}
+	if err := d.logos(logos); err != nil {
+		return err
+	}
+	d.paragraph("Title", []textRun{{Text: labels.Html.GlobalTitle}})
+	d.paragraph("", []textRun{{Text: data.Run.Date.Format("2006-01-02")}})
+	d.pageBreak()
+
+	for _, section := range reportSections(data) {
+		if err := d.section(section, 1); err != nil {
+			return err
+		}
+	}
+	return d.save(outputDir + "/report.docx")
+}
+
+// section writes s and its sub-sections, level being the heading level.
+func (d *docx) section(s reportSection, level int) error {
+	d.paragraph(fmt.Sprintf("Heading%d", level), []textRun{{Text: s.Title}})
+	for _, block := range parseHTMLText(s.Text) {
+		style := ""
+		if block.Bullet {
+			style = "ListBullet"
+			block.Runs = append([]textRun{{Text: "•\t"}}, block.Runs...)
+		}
+		d.paragraph(style, block.Runs)
+	}
+	for _, table := range s.Tables {
+		d.table(table)
+	}
+	for _, c := range s.Charts {
+		if c.empty() {
+			continue
+		}
+		d.paragraph("", []textRun{{Text: c.Title, Bold: true}})
+		img, err := chartPNG(c)
+		if err != nil {
+			return err
+		}
+		if err := d.image(img, 0); err != nil {
+			return err
+		}
+	}
+	for _, sub := range s.Sub {
+		if err := d.section(sub, min(level+1, 2)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// paragraph writes a paragraph of the given style ("" for Normal).
+func (d *docx) paragraph(style string, runs []textRun) {
+	d.body.WriteString("<w:p>")
+	if style != "" {
+		fmt.Fprintf(&d.body, `<w:pPr><w:pStyle w:val="%s"/></w:pPr>`, style)
+	}
+	for _, run := range runs {
+		d.run(run, "")
+	}
+	d.body.WriteString("</w:p>")
+}
+
+// run writes a run of text, colour being an optional hexadecimal colour.
+func (d *docx) run(run textRun, colour string) {
+	d.body.WriteString("<w:r>")
+	if run.Bold || run.Italic || colour != "" {
+		d.body.WriteString("<w:rPr>")
+		if run.Bold {
+			d.body.WriteString("<w:b/>")
+		}
+		if run.Italic {
+			d.body.WriteString("<w:i/>")
+		}
+		if colour != "" {
+			fmt.Fprintf(&d.body, `<w:color w:val="%s"/>`, colour)
+		}
+		d.body.WriteString("</w:rPr>")
+	}
+	parts := strings.Split(run.Text, "\t")
+	for i, part := range parts {
+		if i > 0 {
+			d.body.WriteString("<w:tab/>")
+		}
+		fmt.Fprintf(&d.body, `<w:t xml:space="preserve">%s</w:t>`, xmlEscape(part))
+	}
+	d.body.WriteString("</w:r>")
+}
+
+// table writes t with a shaded header row repeated on every page.
+func (d *docx) table(t reportTable) {
+	if len(t) == 0 {
+		return
+	}
+	width := docxTextWidth / len(t[0])
+	d.body.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="PassTekTable"/><w:tblW w:w="5000" w:type="pct"/></w:tblPr><w:tblGrid>`)
+	for range t[0] {
+		fmt.Fprintf(&d.body, `<w:gridCol w:w="%d"/>`, width)
+	}
+	d.body.WriteString("</w:tblGrid>")
+	for i, row := range t {
+		d.body.WriteString("<w:tr>")
+		if i == 0 {
+			d.body.WriteString("<w:trPr><w:tblHeader/></w:trPr>")
+		}
+		for _, cell := range row {
+			fmt.Fprintf(&d.body, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/>`, width)
+			if i == 0 {
+				d.body.WriteString(`<w:shd w:val="clear" w:color="auto" w:fill="2383C6"/>`)
+			}
+			d.body.WriteString(`</w:tcPr><w:p><w:pPr><w:spacing w:after="0"/></w:pPr>`)
+			if i == 0 {
+				d.run(textRun{Text: cell, Bold: true}, "FFFFFF")
+			} else {
+				d.run(textRun{Text: cell}, "")
+			}
+			d.body.WriteString("</w:p></w:tc>")
+		}
+		d.body.WriteString("</w:tr>")
+	}
+	// Word needs a paragraph between two tables
+	d.body.WriteString("</w:tbl><w:p/>")
+}
+
+// logos writes the logos side by side on the cover.
+func (d *docx) logos(logos [][]byte) error {
+	if len(logos) == 0 {
+		return nil
+	}
+	d.body.WriteString(`<w:p><w:pPr><w:jc w:val="center"/></w:pPr>`)
+	for i, logo := range logos {
+		if i > 0 {
+			d.body.WriteString(`<w:r><w:tab/></w:r>`)
+		}
+		if err := d.drawing(logo, 0, docxLogoHeight); err != nil {
+			return err
+		}
+	}
+	d.body.WriteString("</w:p>")
+	return nil
+}
+
+// image writes img in its own centred paragraph, scaled to width (EMU) or to
+// its own size, within the text width.
+func (d *docx) image(img []byte, width int64) error {
+	d.body.WriteString(`<w:p><w:pPr><w:jc w:val="center"/></w:pPr>`)
+	if err := d.drawing(img, width, 0); err != nil {
+		return err
+	}
+	d.body.WriteString("</w:p>")
+	return nil
+}
+
+// drawing writes an inline picture run. The picture keeps its aspect ratio:
+// it is scaled to width or height (EMU) when set, and never wider than the
+// text.
+func (d *docx) drawing(img []byte, width, height int64) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(img))
+	if err != nil {
+		return err
+	}
+	cx, cy := int64(config.Width)*emuPerPixel, int64(config.Height)*emuPerPixel
+	switch {
+	case width > 0:
+		cx, cy = width, cy*width/cx
+	case height > 0:
+		cx, cy = cx*height/cy, height
+	}
+	if cx > docxImageMax {
+		cx, cy = docxImageMax, cy*docxImageMax/cx
+	}
+
+	d.images = append(d.images, img)
+	id := len(d.images)
+	fmt.Fprintf(&d.body, `<w:r><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0">`+
+		`<wp:extent cx="%[1]d" cy="%[2]d"/><wp:docPr id="%[3]d" name="Picture %[3]d"/>`+
+		`<wp:cNvGraphicFramePr><a:graphicFrameLocks noChangeAspect="1"/></wp:cNvGraphicFramePr>`+
+		`<a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:pic><pic:nvPicPr><pic:cNvPr id="%[3]d" name="image%[3]d.png"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip r:embed="rIdImage%[3]d"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%[1]d" cy="%[2]d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r>`, cx, cy, id)
+	return nil
+}
+
+// pageBreak starts a new page.
+func (d *docx) pageBreak() {
+	d.body.WriteString(`<w:p><w:r><w:br w:type="page"/></w:r></w:p>`)
+}
+
+// save writes the document and its images to path.
+func (d *docx) save(path string) error {
+	var document strings.Builder
+	document.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"` +
+		` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"` +
+		` xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"` +
+		` xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"` +
+		` xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture"><w:body>`)
+	document.WriteString(d.body.String())
+	document.WriteString(`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/>` +
+		`<w:pgMar w:top="1134" w:right="1134" w:bottom="1134" w:left="1134" w:header="567" w:footer="567" w:gutter="0"/>` +
+		`</w:sectPr></w:body></w:document>`)
+
+	var rels strings.Builder
+	rels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+	for i := range d.images {
+		fmt.Fprintf(&rels, `<Relationship Id="rIdImage%[1]d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image%[1]d.png"/>`, i+1)
+	}
+	rels.WriteString(`</Relationships>`)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	parts := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", []byte(docxContentTypes)},
+		{"_rels/.rels", []byte(docxRels)},
+		{"word/document.xml", []byte(document.String())},
+		{"word/styles.xml", []byte(docxStyles)},
+		{"word/_rels/document.xml.rels", []byte(rels.String())},
+	}
+	for i, img := range d.images {
+		parts = append(parts, struct {
+			name string
+			data []byte
+		}{fmt.Sprintf("word/media/image%d.png", i+1), img})
+	}
+	for _, part := range parts {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(part.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// xmlEscape escapes s for XML character data.
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"password-analyzer/utils"
+	"slices"
+	"strings"
 
 	"github.com/xuri/excelize/v2"
 )
@@ -127,6 +129,34 @@ func ToExcel(stats utils.Stats, outputDir string, top int, labels utils.Labels)
 	f.SetCellValue(labels.Reuse.Short, "A3", labels.Reuse.Unique)
 	f.SetCellValue(labels.Reuse.Short, "B3", stats.Hashes.UniqueNTLMHashes)
 
+	// Account-weighted values, side by side with the per-password ones
+	if stats.Weighted.Enabled {
+		weighted := stats.Weighted
+		for _, sheet := range []string{labels.Length.A1, labels.Complexity.A1, labels.Occurrences.A1, labels.Pattern.A1} {
+			f.SetCellValue(sheet, "C1", labels.Weighted.Weighted)
+			f.SetColWidth(sheet, "C", "C", 15)
+		}
+
+		f.SetCellValue(labels.Length.A1, "C2", utils.SumLengthRange(weighted.Lengths, 0, 7))
+		f.SetCellValue(labels.Length.A1, "C3", weighted.Lengths[8])
+		f.SetCellValue(labels.Length.A1, "C4", weighted.Lengths[9])
+		f.SetCellValue(labels.Length.A1, "C5", weighted.Lengths[10])
+		f.SetCellValue(labels.Length.A1, "C6", utils.SumLengthRange(weighted.Lengths, 11, 100))
+
+		for i := 1; i < 5; i++ {
+			f.SetCellValue(labels.Complexity.A1, fmt.Sprintf("C%d", i+1), weighted.Complexity[i])
+		}
+		for i := 0; i < occRows; i++ {
+			f.SetCellValue(labels.Occurrences.A1, fmt.Sprintf("C%d", i+2), weighted.TokenCount[sortedWords[i].Key])
+		}
+		for i := 0; i < patternRows; i++ {
+			f.SetCellValue(labels.Pattern.A1, fmt.Sprintf("C%d", i+2), weighted.Patterns[sortedPattern[i].Key])
+		}
+		if weighted.PerAccount {
+			f.SetCellValue(labels.Length.A1, "A8", labels.Weighted.Identical)
+		}
+	}
+
 	makePie(f, labels.Length.A1, labels.Length.Title, 6)
 	makePie(f, labels.Complexity.A1, labels.Complexity.Title, 6)
 	if occRows > 0 {
@@ -141,12 +171,430 @@ func ToExcel(stats utils.Stats, outputDir string, top int, labels utils.Labels)
 		makePie(f, labels.Mostreuse.Short, labels.Mostreuse.Title, reuseRows+1)
 	}
 
+	// Hash-only mode: nothing was cracked, keep the hash-based sheets only
+	if stats.HashOnly {
+		for _, sheet := range []string{labels.Length.A1, labels.Complexity.A1, labels.Occurrences.A1, labels.Pattern.A1, labels.Mostreuse.Short} {
+			f.DeleteSheet(sheet)
+		}
+		if index, err := f.GetSheetIndex(labels.Reuse.Short); err == nil {
+			f.SetActiveSheet(index)
+		}
+	}
+
+	// Per-account table (requires a hash file)
+	if len(stats.Accounts) > 0 {
+		sheet := labels.Accounts.Short
+		f.NewSheet(sheet)
+		f.SetColWidth(sheet, "A", "B", 25)
+		f.SetColWidth(sheet, "D", "D", 25)
+		f.SetCellValue(sheet, "A1", labels.Accounts.Domain)
+		f.SetCellValue(sheet, "B1", labels.Accounts.Username)
+		f.SetCellValue(sheet, "C1", labels.Accounts.RID)
+		f.SetCellValue(sheet, "D1", labels.Accounts.Password)
+		f.SetCellValue(sheet, "E1", labels.Accounts.Group)
+		f.SetCellValue(sheet, "F1", labels.Age.Status)
+		f.SetCellValue(sheet, "G1", labels.Age.LastSet)
+		f.SetCellValue(sheet, "H1", labels.Privileged.Groups)
+		f.SetCellValue(sheet, "I1", labels.Classes.Class)
+		f.SetColWidth(sheet, "G", "G", 20)
+		f.SetColWidth(sheet, "H", "H", 40)
+		for i, account := range stats.Accounts {
+			row := i + 2
+			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), account.Domain)
+			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), account.Username)
+			f.SetCellValue(sheet, fmt.Sprintf("C%d", row), account.RID)
+			f.SetCellValue(sheet, fmt.Sprintf("D%d", row), account.Password)
+			if account.ReuseGroup > 0 {
+				f.SetCellValue(sheet, fmt.Sprintf("E%d", row), account.ReuseGroup)
+			}
+			f.SetCellValue(sheet, fmt.Sprintf("F%d", row), account.Status)
+			if account.NeverSet {
+				f.SetCellValue(sheet, fmt.Sprintf("G%d", row), labels.Age.NeverSet)
+			} else if !account.PwdLastSet.IsZero() {
+				f.SetCellValue(sheet, fmt.Sprintf("G%d", row), account.PwdLastSet.Format("2006-01-02 15:04"))
+			}
+			f.SetCellValue(sheet, fmt.Sprintf("H%d", row), strings.Join(account.Groups, ", "))
+			f.SetCellValue(sheet, fmt.Sprintf("I%d", row), utils.ClassLabel(labels, account.Class))
+		}
+	}
+
+	// Privileged accounts (-groups): key figures and member list
+	if p := stats.Privileged; p != nil {
+		sheet := labels.Privileged.Short
+		f.NewSheet(sheet)
+		f.SetColWidth(sheet, "A", "A", 35)
+		f.SetColWidth(sheet, "D", "E", 35)
+		f.SetColWidth(sheet, "F", "F", 25)
+		writeKeyFigures(f, sheet, *p, labels)
+		f.SetCellValue(sheet, "D1", labels.Privileged.Account)
+		f.SetCellValue(sheet, "E1", labels.Privileged.Groups)
+		f.SetCellValue(sheet, "F1", labels.Privileged.Password)
+		for i, account := range p.Accounts {
+			row := i + 2
+			f.SetCellValue(sheet, fmt.Sprintf("D%d", row), account.Name())
+			f.SetCellValue(sheet, fmt.Sprintf("E%d", row), strings.Join(account.Groups, ", "))
+			f.SetCellValue(sheet, fmt.Sprintf("F%d", row), account.Password)
+		}
+	}
+
+	// Key figures per account class
+	if len(stats.Classes) > 1 {
+		writeBreakdownSheet(f, labels.Classes.Short, labels.Classes.Class, stats.Classes, utils.ClassLabel, labels)
+	}
+
+	// Key figures per domain, then one sheet per domain
+	if len(stats.Domains) > 0 {
+		writeBreakdownSheet(f, labels.Domains.Short, labels.Domains.Domain, stats.Domains, utils.DomainLabel, labels)
+		// Names of the sheets already created and of the ones created below,
+		// which a domain sheet must not overwrite
+		used := append(f.GetSheetList(), labels.Domains.CrossShort, labels.Age.Short, labels.Clusters.Short,
+			labels.History.Short, labels.PreWin2000.Short, labels.Builtin.Short, labels.Unchanged.Short,
+			labels.AdminLinks.Short, labels.Trend.Short, labels.RIDRanges.Short, labels.Orphans.Short)
+		for _, domain := range stats.Domains {
+			sheet := uniqueSheetName(sheetName(utils.DomainLabel(labels, domain.Name)), used)
+			used = append(used, sheet)
+			f.NewSheet(sheet)
+			f.SetColWidth(sheet, "A", "A", 35)
+			writeKeyFigures(f, sheet, domain.Stats, labels)
+		}
+	}
+
+	// NT hashes shared across domains
+	if len(stats.CrossDomain) > 0 {
+		sheet := labels.Domains.CrossShort
+		f.NewSheet(sheet)
+		f.SetColWidth(sheet, "C", "D", 35)
+		f.SetColWidth(sheet, "E", "E", 100)
+		header := []string{labels.Clusters.Group, labels.Clusters.Size, labels.Clusters.Password, labels.Clusters.Hash, labels.Clusters.Accounts}
+		f.SetSheetRow(sheet, "A1", &header)
+		for i, cluster := range stats.CrossDomain {
+			row := []any{cluster.Group, cluster.Size, cluster.Password, cluster.NTHash, strings.Join(cluster.Accounts, ", ")}
+			f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+2), &row)
+		}
+	}
+
+	// Password age distribution (secretsdump -pwd-last-set)
+	if stats.Age.Known > 0 {
+		sheet := labels.Age.Short
+		f.NewSheet(sheet)
+		f.SetColWidth(sheet, "A", "A", 25)
+		f.SetCellValue(sheet, "A1", labels.Age.A1)
+		f.SetCellValue(sheet, "B1", labels.Age.B1)
+		names := []string{labels.Age.Under90, labels.Age.Under180, labels.Age.Under365, labels.Age.Under730, labels.Age.Under1825, labels.Age.Over1825}
+		for i, name := range names {
+			f.SetCellValue(sheet, fmt.Sprintf("A%d", i+2), name)
+			f.SetCellValue(sheet, fmt.Sprintf("B%d", i+2), stats.Age.Buckets[i])
+		}
+		makePie(f, sheet, labels.Age.Title, len(names)+1)
+	}
+
+	// Reuse clusters, largest first
+	if len(stats.Clusters) > 0 {
+		sheet := labels.Clusters.Short
+		f.NewSheet(sheet)
+		f.SetColWidth(sheet, "C", "D", 35)
+		f.SetColWidth(sheet, "E", "E", 100)
+		f.SetCellValue(sheet, "A1", labels.Clusters.Group)
+		f.SetCellValue(sheet, "B1", labels.Clusters.Size)
+		f.SetCellValue(sheet, "C1", labels.Clusters.Password)
+		f.SetCellValue(sheet, "D1", labels.Clusters.Hash)
+		f.SetCellValue(sheet, "E1", labels.Clusters.Accounts)
+		for i, cluster := range stats.Clusters {
+			row := i + 2
+			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), cluster.Group)
+			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), cluster.Size)
+			f.SetCellValue(sheet, fmt.Sprintf("C%d", row), cluster.Password)
+			f.SetCellValue(sheet, fmt.Sprintf("D%d", row), cluster.NTHash)
+			f.SetCellValue(sheet, fmt.Sprintf("E%d", row), strings.Join(cluster.Accounts, ", "))
+		}
+	}
+
+	// Password history findings, one row per account and finding
+	if stats.History.Accounts > 0 {
+		sheet := labels.History.Short
+		f.NewSheet(sheet)
+		f.SetColWidth(sheet, "A", "B", 35)
+		f.SetColWidth(sheet, "C", "C", 50)
+		f.SetCellValue(sheet, "A1", labels.History.Finding)
+		f.SetCellValue(sheet, "B1", labels.History.Account)
+		f.SetCellValue(sheet, "C1", labels.History.Detail)
+		row := 2
+		for _, account := range stats.History.ReusedCurrent {
+			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), labels.History.ReusedCurrent)
+			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), account)
+			row++
+		}
+		for _, account := range stats.History.Cycling {
+			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), labels.History.Cycling)
+			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), account)
+			row++
+		}
+		for _, change := range stats.History.Incremental {
+			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), labels.History.Incremental)
+			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), change.Account)
+			f.SetCellValue(sheet, fmt.Sprintf("C%d", row), change.Previous+" -> "+change.Current)
+			row++
+		}
+	}
+
+	// Computer accounts with their pre-Windows 2000 default password
+	if len(stats.PreWin2000) > 0 {
+		sheet := labels.PreWin2000.Short
+		f.NewSheet(sheet)
+		f.SetColWidth(sheet, "A", "A", 35)
+		f.SetCellValue(sheet, "A1", labels.PreWin2000.A1)
+		for i, account := range stats.PreWin2000 {
+			f.SetCellValue(sheet, fmt.Sprintf("A%d", i+2), account)
+		}
+	}
+
+	// Well-known accounts (RID 500, 501, 502), one row per finding
+	if b := stats.Builtin; len(b.GuestEmpty) > 0 || len(b.AdminReused) > 0 || len(b.KrbtgtReused) > 0 {
+		sheet := labels.Builtin.Short
+		f.NewSheet(sheet)
+		f.SetColWidth(sheet, "A", "B", 40)
+		f.SetColWidth(sheet, "C", "C", 80)
+		f.SetCellValue(sheet, "A1", labels.Builtin.Finding)
+		f.SetCellValue(sheet, "B1", labels.Builtin.Account)
+		f.SetCellValue(sheet, "C1", labels.Builtin.Detail)
+		row := 2
+		for _, account := range b.GuestEmpty {
+			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), labels.Builtin.GuestEmpty)
+			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), account)
+			row++
+		}
+		findings := []struct {
+			label  string
+			shared []utils.SharedHash
+		}{
+			{labels.Builtin.AdminReused, b.AdminReused},
+			{labels.Builtin.KrbtgtReused, b.KrbtgtReused},
+		}
+		for _, finding := range findings {
+			for _, shared := range finding.shared {
+				f.SetCellValue(sheet, fmt.Sprintf("A%d", row), finding.label)
+				f.SetCellValue(sheet, fmt.Sprintf("B%d", row), shared.Builtin)
+				f.SetCellValue(sheet, fmt.Sprintf("C%d", row), strings.Join(shared.Accounts, ", "))
+				row++
+			}
+		}
+	}
+
+	// Passwords unchanged since the previous audit: figures, then accounts
+	if u := stats.Unchanged; u.Enabled {
+		sheet := labels.Unchanged.Short
+		f.NewSheet(sheet)
+		f.SetColWidth(sheet, "A", "A", 35)
+		f.SetColWidth(sheet, "D", "D", 35)
+		figures := []struct {
+			label string
+			value any
+		}{
+			{labels.Unchanged.Matched, u.Matched},
+			{labels.Unchanged.Unchanged, len(u.Accounts)},
+			{labels.Hash.CrackedRate, utils.Percent(len(u.Accounts), u.Matched)},
+			{labels.Unchanged.Cracked, u.Cracked},
+		}
+		f.SetCellValue(sheet, "A1", labels.Privileged.Figure)
+		f.SetCellValue(sheet, "B1", labels.Privileged.Value)
+		for i, figure := range figures {
+			f.SetCellValue(sheet, fmt.Sprintf("A%d", i+2), figure.label)
+			f.SetCellValue(sheet, fmt.Sprintf("B%d", i+2), figure.value)
+		}
+		f.SetCellValue(sheet, "D1", labels.Unchanged.A1)
+		for i, account := range u.Accounts {
+			f.SetCellValue(sheet, fmt.Sprintf("D%d", i+2), account)
+		}
+	}
+
+	// Personal and administration accounts sharing a password
+	if len(stats.AdminLinks.Shared) > 0 {
+		sheet := labels.AdminLinks.Short
+		f.NewSheet(sheet)
+		f.SetColWidth(sheet, "A", "B", 35)
+		f.SetColWidth(sheet, "C", "C", 50)
+		header := []string{labels.AdminLinks.Personal, labels.AdminLinks.Admin, labels.AdminLinks.Detail}
+		f.SetSheetRow(sheet, "A1", &header)
+		for i, pair := range stats.AdminLinks.Shared {
+			detail := labels.AdminLinks.SameHash
+			if !pair.SameHash {
+				detail = pair.PersonalPassword + " -> " + pair.AdminPassword
+			}
+			row := []any{pair.Personal, pair.Admin, detail}
+			f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+2), &row)
+		}
+	}
+
+	// Key metrics of previous audits (-compare), with a line chart of the
+	// global ones
+	if trend := stats.Trend; trend != nil && len(trend.Metrics) > 0 {
+		sheet := labels.Trend.Short
+		f.NewSheet(sheet)
+		f.SetColWidth(sheet, "A", "A", 35)
+		header := append(append([]string{labels.Trend.Metric}, trend.Dates...), labels.Trend.Delta)
+		f.SetSheetRow(sheet, "A1", &header)
+		var series []excelize.ChartSeries
+		last, _ := excelize.ColumnNumberToName(len(trend.Dates) + 1)
+		for i, metric := range trend.Metrics {
+			row := []any{utils.TrendLabel(labels, metric.Key)}
+			for _, value := range metric.Values {
+				row = append(row, value)
+			}
+			row = append(row, metric.Delta)
+			f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+2), &row)
+			if metric.Group == "global" {
+				series = append(series, excelize.ChartSeries{
+					Name:       fmt.Sprintf("'%s'!$A$%d", sheet, i+2),
+					Categories: fmt.Sprintf("'%s'!$B$1:$%s$1", sheet, last),
+					Values:     fmt.Sprintf("'%s'!$B$%d:$%s$%d", sheet, i+2, last, i+2),
+				})
+			}
+		}
+		if err := f.AddChart(sheet, fmt.Sprintf("A%d", len(trend.Metrics)+3), &excelize.Chart{
+			Type:      excelize.Line,
+			Series:    series,
+			Title:     []excelize.RichTextRun{{Text: labels.Trend.Global}},
+			Dimension: excelize.ChartDimension{Width: 800, Height: 400},
+		}); err != nil {
+			log.Fatalf("[!][ToExcel][AddChart] Failed to add trend chart: %v", err)
+		}
+	}
+
+	// Crack rate per RID range, oldest accounts first
+	if len(stats.Builtin.RIDRanges) > 0 {
+		sheet := labels.RIDRanges.Short
+		f.NewSheet(sheet)
+		f.SetColWidth(sheet, "A", "D", 18)
+		header := []string{labels.RIDRanges.Range, labels.RIDRanges.Accounts, labels.RIDRanges.Cracked, labels.RIDRanges.Rate}
+		f.SetSheetRow(sheet, "A1", &header)
+		for i, r := range stats.Builtin.RIDRanges {
+			row := []any{r.Name(), r.Accounts, r.Cracked, utils.Percent(r.Cracked, r.Accounts)}
+			f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+2), &row)
+		}
+	}
+
+	// Cracked passwords matching no hash of the hash file
+	if len(stats.Orphans) > 0 {
+		sheet := labels.Orphans.Short
+		f.NewSheet(sheet)
+		f.SetColWidth(sheet, "A", "A", 25)
+		f.SetCellValue(sheet, "A1", labels.Orphans.A1)
+		for i, orphan := range stats.Orphans {
+			f.SetCellValue(sheet, fmt.Sprintf("A%d", i+2), orphan)
+		}
+	}
+
 	// Save the Excel file
 	if err := f.SaveAs(outputDir + "/report.xlsx"); err != nil {
 		log.Fatalf("[!][ToExcel][SaveAs] Failed to save Excel file: %v", err)
 	}
 }
 
+// writeKeyFigures fills columns A and B of sheet with the key figures of a
+// subset of accounts: hash statistics, length and complexity distributions
+// and risk level.
+func writeKeyFigures(f *excelize.File, sheet string, stats utils.Stats, labels utils.Labels) {
+	rows := []struct {
+		label string
+		value any
+	}{
+		{labels.Hash.TotalNTLM, stats.Hashes.TotalNTLMHashes},
+		{labels.Hash.Cracked, stats.CrackedCount},
+		{labels.Hash.CrackedRate, utils.Percent(stats.CrackedCount, stats.Hashes.TotalNTLMHashes)},
+		{labels.Hash.UniqueNTLM, stats.Hashes.UniqueNTLMHashes},
+		{labels.Hash.Reused, stats.Hashes.ReusedNTLMHashes},
+		{labels.Hash.LM, stats.Hashes.IsLM},
+		{labels.Hash.EmptyNTLM, stats.Hashes.EmptyNTLMHashes},
+		{labels.Hash.UserEqualHash, len(stats.Hashes.UserEqualHash)},
+		{labels.Length.Short, utils.SumLengthRange(stats.Lengths, 0, 7)},
+		{labels.Length.Exact8, stats.Lengths[8]},
+		{labels.Length.Exact9, stats.Lengths[9]},
+		{labels.Length.Exact10, stats.Lengths[10]},
+		{labels.Length.Long, utils.SumLengthRange(stats.Lengths, 11, 100)},
+		{labels.Complexity.One, stats.Complexity[1]},
+		{labels.Complexity.Two, stats.Complexity[2]},
+		{labels.Complexity.Three, stats.Complexity[3]},
+		{labels.Complexity.Four, stats.Complexity[4]},
+		{labels.Risk.Title, stats.Risk},
+	}
+
+	f.SetCellValue(sheet, "A1", labels.Privileged.Figure)
+	f.SetCellValue(sheet, "B1", labels.Privileged.Value)
+	for i, r := range rows {
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", i+2), r.label)
+		f.SetCellValue(sheet, fmt.Sprintf("B%d", i+2), r.value)
+	}
+}
+
+// writeBreakdownSheet adds a sheet with one row of key figures per subset of
+// accounts, mirroring the breakdown table of the text report.
+func writeBreakdownSheet(f *excelize.File, sheet, column string, breakdown []utils.Breakdown, name func(utils.Labels, string) string, labels utils.Labels) {
+	f.NewSheet(sheet)
+	f.SetColWidth(sheet, "A", "A", 25)
+	f.SetColWidth(sheet, "B", "I", 15)
+	header := []string{
+		column,
+		labels.Hash.TotalNTLM,
+		labels.Hash.Cracked,
+		labels.Hash.CrackedRate,
+		labels.Hash.Reused,
+		labels.Hash.LM,
+		labels.Hash.EmptyNTLM,
+		labels.Hash.UserEqualHash,
+		labels.Risk.Title,
+	}
+	f.SetSheetRow(sheet, "A1", &header)
+	for i, b := range breakdown {
+		s := b.Stats
+		row := []any{
+			name(labels, b.Name),
+			s.Hashes.TotalNTLMHashes,
+			s.CrackedCount,
+			utils.Percent(s.CrackedCount, s.Hashes.TotalNTLMHashes),
+			s.Hashes.ReusedNTLMHashes,
+			s.Hashes.IsLM,
+			s.Hashes.EmptyNTLMHashes,
+			len(s.Hashes.UserEqualHash),
+			s.Risk,
+		}
+		f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+2), &row)
+	}
+}
+
+// sheetName turns name into a valid sheet name: characters forbidden by
+// Excel are replaced and the name is cut to 31 characters.
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	return name
+}
+
+// uniqueSheetName returns name, or name followed by " (2)", " (3)", … and cut
+// to 31 characters, so that it differs from every name of used. Excel sheet
+// names are compared case-insensitively.
+func uniqueSheetName(name string, used []string) string {
+	taken := func(candidate string) bool {
+		return slices.ContainsFunc(used, func(u string) bool { return strings.EqualFold(u, candidate) })
+	}
+	candidate := name
+	for i := 2; taken(candidate); i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		runes := []rune(name)
+		if len(runes) > 31-len(suffix) {
+			runes = runes[:31-len(suffix)]
+		}
+		candidate = string(runes) + suffix
+	}
+	return candidate
+}
+
 // makePie is a small helper that appends a 3-D pie chart to the given sheet.
 // It is kept unexported because chart generation is an internal detail of
 // the Excel export logic.
@@ -1,21 +1,57 @@
 package export
 
 import (
+	"bytes"
+	"fmt"
 	"html/template"
+	"io/fs"
 	"log"
 	"math"
 	"os"
+	assets "password-analyzer"
 	"password-analyzer/utils"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
 )
 
-// ToHtml renders an HTML report using the Go `html/template` engine and the
-// pre-built template found under `export/template/template.html`. The final
-// document is written to `outputDir/report.html` and includes interactive
-// charts (AmCharts) and language-specific strings provided via the Data
-// structure.
-func ToHtml(stats utils.Stats, outputDir string, data utils.Data) {
+// externalResource matches the attributes and CSS rules which make a browser
+// fetch a resource from the network (scripts, styles, images, fonts, frames).
+// Plain hyperlinks (`<a href>`) are not resources and are allowed.
+var externalResource = regexp.MustCompile(`(?i)<(?:script|link|img|image|iframe|frame|source|audio|video|embed|object|use)\b[^>]*?\s(?:src|href|xlink:href|data|srcset|poster)\s*=\s*["']?\s*((?:[a-z][a-z0-9+.-]*:)?//[^"'\s>]*)|url\(\s*["']?\s*((?:[a-z][a-z0-9+.-]*:)?//[^"')\s]*)|@import\s+["']?\s*((?:[a-z][a-z0-9+.-]*:)?//[^"';\s]*)`)
+
+// externalURLs returns the URLs of the external resources loaded by an HTML
+// document or template. The report must be self-contained to be readable on
+// air-gapped networks, so this list is expected to be empty.
+func externalURLs(html []byte) []string {
+	var urls []string
+	for _, match := range externalResource.FindAllStringSubmatch(string(html), -1) {
+		urls = append(urls, match[1]+match[2]+match[3])
+	}
+	return urls
+}
+
+// templateExtensions are the extensions of the files of a template set which
+// are parsed as templates; other files (images, notes, …) are ignored.
+var templateExtensions = []string{".html", ".css", ".js", ".tmpl"}
 
-	funcMap := template.FuncMap{
+// TemplateFuncs returns the functions available to the templates of the HTML
+// report, in addition to the built-in ones of `html/template`:
+//
+//   - sumLengthRange .Stats.Lengths min max: passwords whose length is in [min, max]
+//   - sortMapByValueDesc map: entries (.Key, .Value) of a map, largest value first
+//   - percent part total: part as a percentage of total, one decimal place
+//   - add a b, sub a b: integer arithmetic
+//   - chartSVG id: chart of data as an inline SVG image, empty for an unknown id
+//     or a chart without data
+func TemplateFuncs(data utils.Data) template.FuncMap {
+	charts := make(map[string]chart)
+	for _, c := range reportCharts(data) {
+		charts[c.ID] = c
+	}
+
+	return template.FuncMap{
 		"sumLengthRange":     utils.SumLengthRange,
 		"sortMapByValueDesc": utils.SortMapByValueDesc,
 		"add":                func(a, b int) int { return a + b },
@@ -28,20 +64,81 @@ func ToHtml(stats utils.Stats, outputDir string, data utils.Data) {
 		"sub": func(a, b int) int {
 			return a - b
 		},
+		"chartSVG": func(id string) template.HTML {
+			c, ok := charts[id]
+			if !ok {
+				return ""
+			}
+			return template.HTML(chartSVG(c))
+		},
 	}
+}
 
-	langTmpl := template.Must(template.New("report").Funcs(funcMap).ParseFiles("export/template/template.html"))
+// parseTemplateSet adds the templates of the files of dir to t, each file
+// defining a template named after its base name (template.html, style.css,
+// …). A file replaces the template of the same name already defined. A file
+// which loads an external resource is rejected: the check is done on the
+// templates rather than on the rendered report, whose cracked passwords and
+// usernames may contain anything, e.g. `url(//x)`.
+func parseTemplateSet(t *template.Template, fsys fs.FS, dir string) (*template.Template, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !slices.Contains(templateExtensions, path.Ext(entry.Name())) {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if urls := externalURLs(content); len(urls) > 0 {
+			return nil, fmt.Errorf("%s loads external resources: %s", entry.Name(), strings.Join(urls, ", "))
+		}
+		tmpl := t
+		if entry.Name() != t.Name() {
+			tmpl = t.New(entry.Name())
+		}
+		if _, err = tmpl.Parse(string(content)); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
 
-	path := outputDir + "/report.html"
-	out, err := os.Create(path)
+// ToHtml renders an HTML report using the Go `html/template` engine and the
+// template set found under `export/template/` in the assets: the layout
+// `template.html` includes `style.css`, `cover.html` and `sections.html`. The
+// files of templateDir, when set, replace the files of the same name and may
+// add other partials. The final document is written to
+// `outputDir/report.html` and includes the charts of the Go renderer and
+// language-specific strings provided via the Data structure. The charts are
+// inlined as SVG images with `{{ chartSVG "chart-length" }}`, the argument
+// being the id of the chart element, so the report is a single file which
+// works offline. Rendering fails if a template of the set loads any external
+// resource.
+func ToHtml(stats utils.Stats, outputDir string, data utils.Data, templateDir string) {
+	langTmpl, err := parseTemplateSet(template.New("template.html").Funcs(TemplateFuncs(data)), assets.FS, "export/template")
 	if err != nil {
-		panic(err)
+		log.Fatalf("[!][ToHtml][parseTemplateSet] Failed to parse template: %v", err)
+	}
+	if templateDir != "" {
+		langTmpl, err = parseTemplateSet(langTmpl, os.DirFS(templateDir), ".")
+		if err != nil {
+			log.Fatalf("[!][ToHtml][parseTemplateSet] Failed to parse custom template: %v", err)
+		}
 	}
-	defer out.Close()
 
 	// No need to use .ExecuteTemplate, unless you want to specify a name:
-	err = langTmpl.ExecuteTemplate(out, "template.html", data)
+	var out bytes.Buffer
+	err = langTmpl.ExecuteTemplate(&out, "template.html", data)
 	if err != nil {
 		log.Fatalf("[!][ToHtml][ExecuteTemplate] Failed to execute template: %v", err)
 	}
+
+	path := outputDir + "/report.html"
+	if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+		panic(err)
+	}
 }
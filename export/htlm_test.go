@@ -0,0 +1,111 @@
+package export
+
+import (
+	"encoding/json"
+	"html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	assets "password-analyzer"
+	"password-analyzer/utils"
+)
+
+func TestExternalURLs(t *testing.T) {
+	tests := []struct {
+		html string
+		want []string
+	}{
+		{`<script src="https://cdn.amcharts.com/lib/5/index.js"></script>`, []string{"https://cdn.amcharts.com/lib/5/index.js"}},
+		{`<link rel="stylesheet" href='//fonts.example.com/x.css'>`, []string{"//fonts.example.com/x.css"}},
+		{`<img alt="logo" src=http://example.com/logo.png>`, []string{"http://example.com/logo.png"}},
+		{`<style>body { background: url( "https://example.com/bg.png" ) }</style>`, []string{"https://example.com/bg.png"}},
+		{`<style>@import "//example.com/style.css";</style>`, []string{"//example.com/style.css"}},
+		{`<img src="data:image/png;base64,iVBORw0KGgo=">`, nil},
+		{`<a href="https://github.com/sysdream/PassTek">PassTek</a>`, nil},
+		{`<svg><text>https://example.com</text></svg>`, nil},
+	}
+	for _, tt := range tests {
+		if got := externalURLs([]byte(tt.html)); !slices.Equal(got, tt.want) {
+			t.Errorf("externalURLs(%q) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestParseTemplateSetRejectsExternalResources(t *testing.T) {
+	fsys := fstest.MapFS{
+		"template.html": {Data: []byte(`<html><head>{{ template "style.css" }}</head></html>`)},
+		"style.css":     {Data: []byte(`<style>@font-face { src: url(https://fonts.example.com/a.woff2) }</style>`)},
+	}
+	_, err := parseTemplateSet(template.New("template.html").Funcs(TemplateFuncs(utils.Data{})), fsys, ".")
+	if err == nil || !strings.Contains(err.Error(), "https://fonts.example.com/a.woff2") {
+		t.Errorf("parseTemplateSet accepted a template loading a font from the network: %v", err)
+	}
+}
+
+// TestReportOffline renders the default report and checks that it loads
+// nothing from the network, including when cracked passwords and usernames
+// look like CSS imports or tags: they are user data, not resources, and must
+// not stop the report from being written.
+func TestReportOffline(t *testing.T) {
+	tests := []struct {
+		name      string
+		passwords []string
+	}{
+		{"plain", []string{"Password1", "Spring2024!", "Welcome2023"}},
+		{"hostile", []string{"url(//x)", "@import //x", `"><script src=//x>`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := testData(t, tt.passwords)
+			dir := t.TempDir()
+			ToHtml(data.Stats, dir, data, "")
+			report, err := os.ReadFile(filepath.Join(dir, "report.html"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(report), "<script src") {
+				t.Errorf("report contains a script loaded from a URL")
+			}
+			if tt.name == "plain" {
+				if urls := externalURLs(report); len(urls) > 0 {
+					t.Errorf("report loads external resources: %q", urls)
+				}
+			}
+		})
+	}
+}
+
+// testData returns the data of a report whose accounts use passwords, with
+// the English labels.
+func testData(t *testing.T, passwords []string) utils.Data {
+	var labels utils.Labels
+	content, err := assets.ReadFile("lang/en.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(content, &labels); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := utils.Stats{
+		CrackedCount: len(passwords),
+		TotalCount:   len(passwords),
+		Lengths:      make(map[int]int),
+		Complexity:   map[int]int{3: len(passwords)},
+		Patterns:     map[string]int{"ullllllld": len(passwords)},
+		Mostreuse:    make(map[string]int),
+		TokenCount:   make(map[string]int),
+		Top:          5,
+	}
+	for i, password := range passwords {
+		stats.Lengths[len(password)]++
+		stats.Mostreuse[password] = 2
+		stats.TokenCount[password] = 2
+		stats.Accounts = append(stats.Accounts, utils.Account{Domain: "CORP", Username: password, RID: 1100 + i, Password: password, Cracked: true})
+	}
+	return utils.Data{Stats: stats, Labels: labels}
+}
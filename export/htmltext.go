@@ -0,0 +1,77 @@
+package export
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// textRun is a piece of text of a paragraph with its emphasis.
+type textRun struct {
+	Text   string
+	Bold   bool
+	Italic bool
+}
+
+// textBlock is a paragraph, or a list item when Bullet is set.
+type textBlock struct {
+	Bullet bool
+	Runs   []textRun
+}
+
+// htmlToken matches a tag or the text between two tags.
+var htmlToken = regexp.MustCompile(`(?s)<[^>]*>|[^<]+`)
+
+// htmlTagName captures the name of a tag.
+var htmlTagName = regexp.MustCompile(`^</?\s*([A-Za-z0-9]*)`)
+
+// htmlSpaces matches the white space collapsed by HTML rendering.
+var htmlSpaces = regexp.MustCompile(`\s+`)
+
+// parseHTMLText turns the small HTML subset of the localised texts
+// (Labels.Html) into paragraphs: <br>, <ul> and <li> start a new paragraph,
+// <b>/<strong> and <i>/<em> set the emphasis and other tags are dropped.
+func parseHTMLText(text string) []textBlock {
+	var blocks []textBlock
+	current := textBlock{}
+	bold, italic := false, false
+
+	flush := func() {
+		if len(current.Runs) > 0 {
+			current.Runs[0].Text = strings.TrimLeft(current.Runs[0].Text, " ")
+			last := &current.Runs[len(current.Runs)-1]
+			last.Text = strings.TrimRight(last.Text, " ")
+			blocks = append(blocks, current)
+		}
+		current = textBlock{}
+	}
+
+	for _, token := range htmlToken.FindAllString(text, -1) {
+		if !strings.HasPrefix(token, "<") {
+			t := htmlSpaces.ReplaceAllString(html.UnescapeString(token), " ")
+			if len(current.Runs) == 0 {
+				t = strings.TrimLeft(t, " ")
+			}
+			if t != "" {
+				current.Runs = append(current.Runs, textRun{Text: t, Bold: bold, Italic: italic})
+			}
+			continue
+		}
+
+		name := strings.ToLower(htmlTagName.FindStringSubmatch(token)[1])
+		closing := strings.HasPrefix(token, "</")
+		switch name {
+		case "b", "strong":
+			bold = !closing
+		case "i", "em":
+			italic = !closing
+		case "br", "p", "ul", "ol", "div":
+			flush()
+		case "li":
+			flush()
+			current.Bullet = !closing
+		}
+	}
+	flush()
+	return blocks
+}
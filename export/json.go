@@ -0,0 +1,51 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+
+	"password-analyzer/utils"
+)
+
+// JSONSchemaVersion is the version of the report.json format, increased on
+// every incompatible change of its layout or of Stats. Version 2 uses
+// camelCase keys everywhere and leaves the LM and NT hashes out.
+const JSONSchemaVersion = 2
+
+// jsonReport is the layout of report.json.
+type jsonReport struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	Run           utils.RunMetadata `json:"run"`
+	Risk          jsonRisk          `json:"risk"`
+	Stats         utils.Stats       `json:"stats"`
+}
+
+// jsonRisk is the risk level of the whole hash file and how it was scored.
+type jsonRisk struct {
+	Level  string            `json:"level"`
+	Score  float64           `json:"score"`
+	Inputs []utils.RiskInput `json:"inputs"`
+}
+
+// ToJSON writes a `report.json` file inside outputDir for tooling and
+// dashboards: the complete statistics, with full maps rather than the top N
+// entries of the other reports, the risk inputs and the run metadata. The
+// hashes of the accounts, of their history and of the reuse clusters are not
+// exported (`json:"-"`), so the file holds no pass-the-hash material.
+func ToJSON(data utils.Data, outputDir string) error {
+	report := jsonReport{
+		SchemaVersion: JSONSchemaVersion,
+		Run:           data.Run,
+		Risk: jsonRisk{
+			Level:  data.Stats.Risk,
+			Score:  data.Stats.GlobalPercent,
+			Inputs: data.Stats.RiskInputs,
+		},
+		Stats: data.Stats,
+	}
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputDir+"/report.json", out, 0600)
+}
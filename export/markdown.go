@@ -0,0 +1,412 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"password-analyzer/utils"
+)
+
+// markdownEscaper escapes the characters with a meaning in Markdown text and
+// tables, as passwords and usernames may contain any of them.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"|", `\|`,
+	"\n", " ",
+)
+
+// markdownURL matches the links of the localised texts, which are left
+// unescaped for Markdown renderers to turn them into links.
+var markdownURL = regexp.MustCompile(`https?://\S+`)
+
+// ToMarkdown writes a `report.md` file inside outputDir with the sections
+// of the text report as Markdown headings and tables, for Markdown-based
+// reporting tools. The summary and remediation texts are the localised HTML
+// ones (Labels.Html) converted to Markdown.
+func ToMarkdown(data utils.Data, outputDir string, top int) error {
+	f, err := os.Create(outputDir + "/report.md")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stats, labels := data.Stats, data.Labels
+	fmt.Fprintf(f, "# %s\n", markdownEscaper.Replace(labels.Html.GlobalTitle))
+
+	writeMarkdownHeading(f, "##", string(labels.Html.Summary.Title))
+	writeMarkdownText(f, string(labels.Html.Summary.Text))
+	writeMarkdownTable(f, reportTable{
+		{labels.Privileged.Figure, labels.Privileged.Value},
+		{labels.Risk.Title, fmt.Sprintf("%s (%.2f / 100)", stats.Risk, stats.GlobalPercent)},
+	})
+
+	writeMarkdownStats(f, stats, top, labels, "##")
+
+	// Comparison with previous audits (-compare)
+	if stats.Trend != nil {
+		writeMarkdownHeading(f, "##", labels.Trend.Title)
+		writeMarkdownTable(f, trendTable(*stats.Trend, labels))
+	}
+
+	// Same report restricted to privileged accounts (-groups)
+	if p := stats.Privileged; p != nil {
+		writeMarkdownHeading(f, "##", labels.Privileged.Title)
+		members := reportTable{{labels.Privileged.Account, labels.Privileged.Groups, labels.Privileged.Password}}
+		for _, a := range p.Accounts {
+			members = append(members, []string{a.Name(), strings.Join(a.Groups, ", "), a.Password})
+		}
+		writeMarkdownTable(f, members)
+		writeMarkdownStats(f, *p, top, labels, "###")
+	}
+
+	// Same report for every account class
+	if len(stats.Classes) > 1 {
+		writeMarkdownHeading(f, "##", labels.Classes.Title)
+		writeMarkdownTable(f, breakdownTable(labels.Classes.Class, stats.Classes, utils.ClassLabel, labels))
+		for _, class := range stats.Classes {
+			writeMarkdownHeading(f, "##", labels.Classes.Class+" : "+utils.ClassLabel(labels, class.Name))
+			writeMarkdownStats(f, class.Stats, top, labels, "###")
+		}
+	}
+
+	// Same report for every domain of a merged hash file
+	if len(stats.Domains) > 0 {
+		writeMarkdownHeading(f, "##", labels.Domains.Title)
+		writeMarkdownTable(f, breakdownTable(labels.Domains.Domain, stats.Domains, utils.DomainLabel, labels))
+		for _, domain := range stats.Domains {
+			writeMarkdownHeading(f, "##", labels.Domains.Domain+" : "+utils.DomainLabel(labels, domain.Name))
+			writeMarkdownStats(f, domain.Stats, top, labels, "###")
+		}
+	}
+
+	writeMarkdownHeading(f, "##", string(labels.Html.Remediation.Title))
+	writeMarkdownText(f, string(labels.Html.Remediation.Text))
+	return nil
+}
+
+// writeMarkdownStats writes every section of the text report for stats,
+// level being the heading of the sections ("##" or "###").
+func writeMarkdownStats(w io.Writer, stats utils.Stats, top int, labels utils.Labels, level string) {
+	// Hash analysis
+	if stats.Hashes.IsHash {
+		writeMarkdownHeading(w, level, labels.Hash.Title)
+		table := reportTable{{labels.Privileged.Figure, labels.Privileged.Value}}
+		if p := stats.Privileged; p != nil && !p.HashOnly {
+			table = append(table, []string{labels.Privileged.Headline, fmt.Sprintf("%d / %d (%.1f%%)", p.CrackedCount, p.Hashes.TotalNTLMHashes, utils.Percent(p.CrackedCount, p.Hashes.TotalNTLMHashes))})
+		}
+		table = append(table, []string{labels.Hash.TotalNTLM, fmt.Sprint(stats.Hashes.TotalNTLMHashes)})
+		if !stats.HashOnly {
+			table = append(table, []string{labels.Hash.Cracked, fmt.Sprint(stats.CrackedCount)})
+		}
+		table = append(table,
+			[]string{labels.Hash.UniqueNTLM, fmt.Sprint(stats.Hashes.UniqueNTLMHashes)},
+			[]string{labels.Hash.Reused, fmt.Sprint(stats.Hashes.ReusedNTLMHashes)},
+			[]string{labels.Hash.LM, fmt.Sprint(stats.Hashes.IsLM)},
+			[]string{labels.Hash.EmptyNTLM, fmt.Sprint(stats.Hashes.EmptyNTLMHashes)},
+		)
+		if len(stats.Hashes.UserEqualHash) > 0 {
+			table = append(table, []string{labels.Hash.UserEqualHash, fmt.Sprint(len(stats.Hashes.UserEqualHash))})
+		}
+		if stats.Hashes.Disabled > 0 {
+			disabled := fmt.Sprint(stats.Hashes.Disabled)
+			if !stats.Hashes.DisabledIncluded {
+				disabled += " (" + labels.Hash.Excluded + ")"
+			}
+			table = append(table,
+				[]string{labels.Hash.Disabled, disabled},
+				[]string{labels.Hash.DisabledCrack, fmt.Sprint(stats.Hashes.DisabledCracked)},
+			)
+		}
+		if stats.Hashes.Machines > 0 {
+			machines := fmt.Sprint(stats.Hashes.Machines)
+			if !stats.Hashes.MachinesIncluded {
+				machines += " (" + labels.Hash.Excluded + ")"
+			}
+			table = append(table, []string{labels.Classes.Machines, machines})
+		}
+		writeMarkdownTable(w, table)
+	}
+
+	// Cracked password analysis (skipped in hash-only mode)
+	if !stats.HashOnly {
+		writeMarkdownPasswordSections(w, stats, top, labels, level)
+	}
+
+	// Cracked accounts (requires a hash file)
+	if len(stats.Accounts) > 0 && !stats.HashOnly {
+		writeMarkdownHeading(w, level, labels.Accounts.Title)
+		table := reportTable{{labels.Accounts.Username, labels.Accounts.RID, labels.Accounts.Password, labels.Accounts.Group}}
+		for _, a := range stats.Accounts {
+			if !a.Cracked {
+				continue
+			}
+			group := ""
+			if a.ReuseGroup > 0 {
+				group = fmt.Sprintf("#%d", a.ReuseGroup)
+			}
+			table = append(table, []string{a.Name(), fmt.Sprint(a.RID), a.Password, group})
+		}
+		writeMarkdownTable(w, table)
+	}
+
+	// Accounts sharing an NT hash, largest cluster first
+	if len(stats.Clusters) > 0 {
+		writeMarkdownHeading(w, level, labels.Clusters.Title)
+		writeMarkdownTable(w, clustersTable(stats.Clusters, labels))
+	}
+
+	// NT hashes shared across domains
+	if len(stats.CrossDomain) > 0 {
+		writeMarkdownHeading(w, level, labels.Domains.CrossTitle)
+		writeMarkdownTable(w, clustersTable(stats.CrossDomain, labels))
+	}
+
+	// Previous passwords (secretsdump -history)
+	if h := stats.History; h.Accounts > 0 {
+		writeMarkdownHeading(w, level, labels.History.Title)
+		writeMarkdownTable(w, reportTable{
+			{labels.Privileged.Figure, labels.Privileged.Value},
+			{labels.History.Accounts, fmt.Sprint(h.Accounts)},
+			{labels.History.ReusedCurrent, fmt.Sprint(len(h.ReusedCurrent))},
+			{labels.History.Cycling, fmt.Sprint(len(h.Cycling))},
+			{labels.History.Incremental, fmt.Sprint(len(h.Incremental))},
+		})
+		if len(h.ReusedCurrent)+len(h.Cycling)+len(h.Incremental) > 0 {
+			writeMarkdownTable(w, historyTable(h, labels))
+		}
+	}
+
+	// Password age (secretsdump -pwd-last-set)
+	if age := stats.Age; age.Known > 0 || len(age.NeverSet) > 0 {
+		writeMarkdownHeading(w, level, labels.Age.Title)
+		names := []string{labels.Age.Under90, labels.Age.Under180, labels.Age.Under365, labels.Age.Under730, labels.Age.Under1825, labels.Age.Over1825}
+		table := reportTable{{labels.Age.A1, labels.Age.B1}}
+		for i, name := range names {
+			table = append(table, []string{name, fmt.Sprint(age.Buckets[i])})
+		}
+		table = append(table,
+			[]string{labels.Age.Known, fmt.Sprint(age.Known)},
+			[]string{labels.Age.NeverSet, fmt.Sprint(len(age.NeverSet))},
+			[]string{labels.Age.Older, fmt.Sprint(len(age.Older))},
+		)
+		writeMarkdownTable(w, table)
+		if len(age.NeverSet) > 0 {
+			writeMarkdownTable(w, listTable(labels.Age.NeverSet, age.NeverSet))
+		}
+		if len(age.Older) > 0 {
+			writeMarkdownTable(w, listTable(labels.Age.Older, age.Older))
+		}
+	}
+
+	// Computer accounts with their pre-Windows 2000 default password
+	if len(stats.PreWin2000) > 0 {
+		writeMarkdownHeading(w, level, fmt.Sprintf("%s (%d)", labels.PreWin2000.Title, len(stats.PreWin2000)))
+		writeMarkdownTable(w, listTable(labels.PreWin2000.A1, stats.PreWin2000))
+	}
+
+	// Well-known accounts (RID 500, 501, 502)
+	if b := stats.Builtin; len(b.GuestEmpty) > 0 || len(b.AdminReused) > 0 || len(b.KrbtgtReused) > 0 {
+		writeMarkdownHeading(w, level, labels.Builtin.Title)
+		writeMarkdownTable(w, builtinTable(b, labels))
+	}
+
+	// Passwords unchanged since the previous audit
+	if u := stats.Unchanged; u.Enabled {
+		writeMarkdownHeading(w, level, labels.Unchanged.Title)
+		writeMarkdownTable(w, unchangedTable(stats, labels))
+		if len(u.Accounts) > 0 {
+			writeMarkdownTable(w, listTable(labels.Unchanged.A1, u.Accounts))
+		}
+	}
+
+	// Personal and administration accounts sharing a password
+	if links := stats.AdminLinks; links.Pairs > 0 {
+		writeMarkdownHeading(w, level, labels.AdminLinks.Title)
+		writeMarkdownTable(w, reportTable{
+			{labels.Privileged.Figure, labels.Privileged.Value},
+			{labels.AdminLinks.Pairs, fmt.Sprint(links.Pairs)},
+			{labels.AdminLinks.Shared, fmt.Sprint(len(links.Shared))},
+		})
+		if len(links.Shared) > 0 {
+			writeMarkdownTable(w, adminLinksTable(links, labels))
+		}
+	}
+
+	// Crack rate of old and recent accounts
+	if len(stats.Builtin.RIDRanges) > 0 {
+		writeMarkdownHeading(w, level, labels.RIDRanges.Title)
+		writeMarkdownTable(w, ridRangesTable(stats.Builtin.RIDRanges, labels))
+	}
+
+	// Cracked passwords that could not be attributed to any account
+	if len(stats.Orphans) > 0 {
+		writeMarkdownHeading(w, level, fmt.Sprintf("%s (%d)", labels.Orphans.Title, len(stats.Orphans)))
+		writeMarkdownTable(w, listTable(labels.Orphans.A1, stats.Orphans))
+	}
+}
+
+// writeMarkdownPasswordSections writes the sections computed from cracked
+// passwords (reuse, length, complexity, occurrences, patterns, most reused).
+func writeMarkdownPasswordSections(w io.Writer, stats utils.Stats, top int, labels utils.Labels, level string) {
+	weighted := stats.Weighted
+
+	// Reuse summary (reused vs unique) if no hash is provided
+	if !stats.Hashes.IsHash {
+		writeMarkdownHeading(w, level, labels.Reuse.Title)
+		writeMarkdownTable(w, reportTable{
+			{labels.Reuse.A1, labels.Reuse.B1},
+			{labels.Reuse.Total, fmt.Sprint(stats.CrackedCount)},
+			{labels.Reuse.Unique, fmt.Sprint(stats.CrackedCount - stats.Hashes.ReusedNTLMHashes)},
+			{labels.Reuse.Short, fmt.Sprint(stats.Hashes.ReusedNTLMHashes)},
+		})
+	}
+
+	// Length analysis
+	if weighted.PerAccount {
+		fmt.Fprintf(w, "\n%s\n", markdownEscaper.Replace(labels.Weighted.Identical))
+	}
+	writeMarkdownHeading(w, level, labels.Length.Title)
+	lengths := weightedTable(labels.Length.A1, labels.Length.B1, weighted, labels)
+	lengths = weightedRow(lengths, labels.Length.Short, utils.SumLengthRange(stats.Lengths, 0, 7), weighted, utils.SumLengthRange(weighted.Lengths, 0, 7))
+	lengths = weightedRow(lengths, labels.Length.Exact8, stats.Lengths[8], weighted, weighted.Lengths[8])
+	lengths = weightedRow(lengths, labels.Length.Exact9, stats.Lengths[9], weighted, weighted.Lengths[9])
+	lengths = weightedRow(lengths, labels.Length.Exact10, stats.Lengths[10], weighted, weighted.Lengths[10])
+	lengths = weightedRow(lengths, labels.Length.Long, utils.SumLengthRange(stats.Lengths, 11, 100), weighted, utils.SumLengthRange(weighted.Lengths, 11, 100))
+	writeMarkdownTable(w, lengths)
+
+	// Complexity analysis
+	writeMarkdownHeading(w, level, labels.Complexity.Title)
+	complexity := weightedTable(labels.Complexity.A1, labels.Complexity.B1, weighted, labels)
+	complexity = weightedRow(complexity, labels.Complexity.One, stats.Complexity[1], weighted, weighted.Complexity[1])
+	complexity = weightedRow(complexity, labels.Complexity.Two, stats.Complexity[2], weighted, weighted.Complexity[2])
+	complexity = weightedRow(complexity, labels.Complexity.Three, stats.Complexity[3], weighted, weighted.Complexity[3])
+	complexity = weightedRow(complexity, labels.Complexity.Four, stats.Complexity[4], weighted, weighted.Complexity[4])
+	writeMarkdownTable(w, complexity)
+
+	// Occurrences analysis
+	writeMarkdownHeading(w, level, labels.Occurrences.Title)
+	tokens := weightedTable(labels.Occurrences.A1, labels.Occurrences.B1, weighted, labels)
+	for _, e := range topEntries(stats.TokenCount, top) {
+		tokens = weightedRow(tokens, e.Key, e.Value, weighted, weighted.TokenCount[e.Key])
+	}
+	writeMarkdownTable(w, tokens)
+
+	// Pattern analysis
+	writeMarkdownHeading(w, level, labels.Pattern.Title)
+	fmt.Fprintf(w, "\nl = %s, u = %s, d = %s, s = %s\n",
+		markdownEscaper.Replace(labels.Pattern.L),
+		markdownEscaper.Replace(labels.Pattern.U),
+		markdownEscaper.Replace(labels.Pattern.D),
+		markdownEscaper.Replace(labels.Pattern.S))
+	patterns := weightedTable(labels.Pattern.A1, labels.Pattern.B1, weighted, labels)
+	for _, e := range topEntries(stats.Patterns, top) {
+		patterns = weightedRow(patterns, e.Key, e.Value, weighted, weighted.Patterns[e.Key])
+	}
+	writeMarkdownTable(w, patterns)
+
+	// Most reuse analysis
+	writeMarkdownHeading(w, level, labels.Mostreuse.Title)
+	writeMarkdownTable(w, topTable(labels.Mostreuse.A1, labels.Mostreuse.B1, stats.Mostreuse, top))
+}
+
+// weightedTable returns the header of a label/value table, with a column of
+// account-weighted values when they are available.
+func weightedTable(column, value string, weighted utils.WeightedStats, labels utils.Labels) reportTable {
+	if !weighted.Enabled {
+		return reportTable{{column, value}}
+	}
+	return reportTable{{column, labels.Weighted.Unweighted, labels.Weighted.Weighted}}
+}
+
+// weightedRow appends a row to a table returned by weightedTable.
+func weightedRow(table reportTable, label string, value int, weighted utils.WeightedStats, weightedValue int) reportTable {
+	if !weighted.Enabled {
+		return append(table, []string{label, fmt.Sprint(value)})
+	}
+	return append(table, []string{label, fmt.Sprint(value), fmt.Sprint(weightedValue)})
+}
+
+// writeMarkdownHeading writes a heading, level being "##" or "###".
+func writeMarkdownHeading(w io.Writer, level, title string) {
+	fmt.Fprintf(w, "\n%s %s\n", level, markdownEscaper.Replace(title))
+}
+
+// writeMarkdownTable writes table as a Markdown table, its first row being
+// the header. Nothing is written for a table without rows.
+func writeMarkdownTable(w io.Writer, table reportTable) {
+	if len(table) < 2 {
+		return
+	}
+	fmt.Fprintln(w)
+	for i, row := range table {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cells[j] = markdownEscaper.Replace(cell)
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+		if i == 0 {
+			fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(row)))
+		}
+	}
+}
+
+// writeMarkdownText writes a localised HTML text as Markdown paragraphs and
+// lists, keeping the bold and italic emphasis.
+func writeMarkdownText(w io.Writer, text string) {
+	list := false
+	for _, block := range parseHTMLText(text) {
+		// Items of the same list are not separated by a blank line
+		if !block.Bullet || !list {
+			fmt.Fprintln(w)
+		}
+		list = block.Bullet
+		if block.Bullet {
+			fmt.Fprint(w, "- ")
+		}
+		for _, run := range block.Runs {
+			fmt.Fprint(w, markdownRun(run))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// markdownRun returns run with its emphasis markers, the surrounding spaces
+// being kept outside of them as Markdown requires.
+func markdownRun(run textRun) string {
+	text := strings.TrimSpace(run.Text)
+	if text == "" || !run.Bold && !run.Italic {
+		return markdownText(run.Text)
+	}
+	marker := "*"
+	if run.Bold && run.Italic {
+		marker = "***"
+	} else if run.Bold {
+		marker = "**"
+	}
+	lead := run.Text[:len(run.Text)-len(strings.TrimLeft(run.Text, " \t"))]
+	trail := run.Text[len(strings.TrimRight(run.Text, " \t")):]
+	return lead + marker + markdownText(text) + marker + trail
+}
+
+// markdownText escapes text, except for its links.
+func markdownText(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, link := range markdownURL.FindAllStringIndex(text, -1) {
+		b.WriteString(markdownEscaper.Replace(text[last:link[0]]))
+		b.WriteString(text[link[0]:link[1]])
+		last = link[1]
+	}
+	b.WriteString(markdownEscaper.Replace(text[last:]))
+	return b.String()
+}
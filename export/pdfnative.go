@@ -0,0 +1,652 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+
+	"password-analyzer/utils"
+)
+
+// Layout of the native PDF report, in points (A4 page).
+const (
+	pdfPageWidth    = 595.28
+	pdfPageHeight   = 841.89
+	pdfMargin       = 50.0
+	pdfContentWidth = pdfPageWidth - 2*pdfMargin
+	pdfTextSize     = 10.0
+	pdfLeading      = 14.0
+	pdfTableSize    = 8.5
+	pdfTableLeading = 11.0
+	pdfCellPadding  = 4.0
+)
+
+// pdfFont is one of the standard PDF fonts, which every reader provides.
+type pdfFont int
+
+const (
+	pdfRegular pdfFont = iota
+	pdfBold
+	pdfItalic
+)
+
+// pdfFontNames are the base fonts of pdfRegular, pdfBold and pdfItalic.
+var pdfFontNames = []string{"Helvetica", "Helvetica-Bold", "Helvetica-Oblique"}
+
+// helveticaWidths and helveticaBoldWidths are the advance widths of the
+// printable ASCII characters (32 to 126), in thousandths of the font size.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// Colours of the native PDF report, those of the HTML template.
+var (
+	pdfBlue   = color.RGBA{0x23, 0x83, 0xc6, 0xff}
+	pdfInk    = color.RGBA{0x2c, 0x3e, 0x50, 0xff}
+	pdfGrey   = color.RGBA{0x88, 0x93, 0x9e, 0xff}
+	pdfBorder = color.RGBA{0xdd, 0xe3, 0xea, 0xff}
+	pdfStripe = color.RGBA{0xf4, 0xf7, 0xfa, 0xff}
+	pdfWhite  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+)
+
+// pdfWord is a word of a paragraph with its font. Space is set when the word
+// is preceded by a space.
+type pdfWord struct {
+	Text  string
+	Font  pdfFont
+	Space bool
+}
+
+// pdfImage is a decoded image, its alpha channel kept apart as PDF requires.
+type pdfImage struct {
+	Width, Height int
+	RGB, Alpha    []byte // Alpha is nil for an opaque image
+}
+
+// pdfDoc lays out the native PDF report: one content stream per page and the
+// images they draw.
+type pdfDoc struct {
+	title  string
+	pages  []*bytes.Buffer
+	page   *bytes.Buffer
+	y      float64 // Top of the free space of the current page
+	images []pdfImage
+}
+
+// ToNativePDF writes a `report.pdf` file inside outputDir with the sections
+// of the HTML report: cover page with the logos, summary with the risk,
+// tables and charts drawn as vector graphics. Unlike ToPDF, it is written in
+// Go and needs no browser.
+func ToNativePDF(data utils.Data, outputDir string) error {
+	labels := data.Labels
+	d := &pdfDoc{title: labels.Html.GlobalTitle}
+	if err := d.cover(data); err != nil {
+		return err
+	}
+
+	d.newPage()
+	for _, section := range reportSections(data) {
+		d.section(section, 1)
+	}
+	return d.save(outputDir + "/report.pdf")
+}
+
+// cover draws the cover page: the logos, the title, the date and the risk.
+func (d *pdfDoc) cover(data utils.Data) error {
+	labels := data.Labels
+	d.newPage()
+
+	// Company logo on the left, client logo on the right
+	logos := []struct {
+		hidden, base64 string
+		right          bool
+	}{
+		{labels.Html.IsLogo, labels.Html.Logo64, false},
+		{labels.Html.IsClientLogo, labels.Html.ClientLogo64, true},
+	}
+	for _, logo := range logos {
+		if logo.hidden == "hidden" || logo.base64 == "" {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(logo.base64)
+		if err != nil {
+			return err
+		}
+		id, err := d.addImage(raw)
+		if err != nil {
+			return err
+		}
+		img := d.images[id]
+		height := 50.0
+		width := height * float64(img.Width) / float64(img.Height)
+		if width > pdfContentWidth/2 {
+			width, height = pdfContentWidth/2, pdfContentWidth/2*float64(img.Height)/float64(img.Width)
+		}
+		x := pdfMargin
+		if logo.right {
+			x = pdfPageWidth - pdfMargin - width
+		}
+		fmt.Fprintf(d.page, "q %s 0 0 %s %s %s cm /Im%d Do Q\n", pdfNum(width), pdfNum(height), pdfNum(x), pdfNum(pdfPageHeight-pdfMargin-height), id+1)
+	}
+
+	y := pdfPageHeight * 0.58
+	for _, line := range wrapText(labels.Html.GlobalTitle, pdfBold, 26, pdfContentWidth) {
+		d.centredText(y, line, pdfBold, 26, pdfBlue)
+		y -= 34
+	}
+	d.rect(pdfPageWidth/2-60, y+12, 120, 2, pdfBlue)
+	d.centredText(y-14, data.Run.Date.Format("2006-01-02"), pdfRegular, 12, pdfGrey)
+
+	risk := fmt.Sprintf("%s : %s (%.2f / 100)", labels.Risk.Title, data.Stats.Risk, data.Stats.GlobalPercent)
+	width := textWidth(risk, pdfBold, 14) + 40
+	d.rect((pdfPageWidth-width)/2, y-80, width, 30, gaugeColour(data.Stats.GlobalPercent))
+	d.centredText(y-70, risk, pdfBold, 14, pdfInk)
+	return nil
+}
+
+// section draws s and its sub-sections, level being the heading level.
+func (d *pdfDoc) section(s reportSection, level int) {
+	d.heading(level, s.Title)
+	for _, block := range parseHTMLText(s.Text) {
+		d.paragraph(block)
+	}
+	for _, table := range s.Tables {
+		d.table(table)
+	}
+	for _, c := range s.Charts {
+		if !c.empty() {
+			d.chart(c)
+		}
+	}
+	for _, sub := range s.Sub {
+		d.section(sub, 2)
+	}
+}
+
+// newPage starts a new page.
+func (d *pdfDoc) newPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// ensure starts a new page when less than height is left on the current one.
+// It reports whether a page was started.
+func (d *pdfDoc) ensure(height float64) bool {
+	// The bottom margin also holds the footer
+	if d.y-height < pdfMargin+10 {
+		d.newPage()
+		return true
+	}
+	return false
+}
+
+// heading draws a level 1 (underlined) or level 2 heading, kept with at least
+// a few lines of the section.
+func (d *pdfDoc) heading(level int, title string) {
+	size := 16.0
+	if level > 1 {
+		size = 12.5
+	}
+	lines := wrapText(title, pdfBold, size, pdfContentWidth)
+	d.ensure(float64(len(lines))*size*1.3 + 12 + 4*pdfLeading)
+	d.y -= 10
+	for _, line := range lines {
+		d.y -= size * 1.3
+		d.text(pdfMargin, d.y, line, pdfBold, size, pdfBlue)
+	}
+	if level == 1 {
+		d.rect(pdfMargin, d.y-5, pdfContentWidth, 1, pdfBlue)
+		d.y -= 5
+	}
+	d.y -= 6
+}
+
+// paragraph draws a block of localised text, wrapped on the content width.
+func (d *pdfDoc) paragraph(block textBlock) {
+	left, width := pdfMargin, pdfContentWidth
+	if block.Bullet {
+		left, width = pdfMargin+14, pdfContentWidth-14
+	}
+
+	// Split the runs into words, keeping the spaces between runs
+	var words []pdfWord
+	space := false
+	for _, run := range block.Runs {
+		font := pdfRegular
+		if run.Bold {
+			font = pdfBold
+		} else if run.Italic {
+			font = pdfItalic
+		}
+		if strings.HasPrefix(run.Text, " ") {
+			space = true
+		}
+		for _, word := range strings.Fields(run.Text) {
+			words = append(words, pdfWord{Text: word, Font: font, Space: space})
+			space = true
+		}
+		space = strings.HasSuffix(run.Text, " ")
+	}
+
+	for i, line := range wrapWords(words, pdfTextSize, width) {
+		d.ensure(pdfLeading)
+		d.y -= pdfLeading
+		if i == 0 && block.Bullet {
+			d.text(pdfMargin+3, d.y, "•", pdfRegular, pdfTextSize, pdfBlue)
+		}
+		x := left
+		for j, word := range line {
+			if j > 0 && word.Space {
+				x += textWidth(" ", word.Font, pdfTextSize)
+			}
+			d.text(x, d.y, word.Text, word.Font, pdfTextSize, pdfInk)
+			x += textWidth(word.Text, word.Font, pdfTextSize)
+		}
+	}
+	d.y -= 4
+}
+
+// table draws t with a blue header row, repeated on every page, and striped
+// rows. Columns get a width in proportion to their content and cells wrap;
+// rows taller than a page are split across pages.
+func (d *pdfDoc) table(t reportTable) {
+	if len(t) == 0 {
+		return
+	}
+	widths := make([]float64, len(t[0]))
+	var total float64
+	for i := range widths {
+		for j, row := range t {
+			font := pdfRegular
+			if j == 0 {
+				font = pdfBold
+			}
+			if i < len(row) {
+				widths[i] = max(widths[i], textWidth(row[i], font, pdfTableSize)+2*pdfCellPadding)
+			}
+		}
+		widths[i] = min(widths[i], pdfContentWidth)
+		total += widths[i]
+	}
+	for i := range widths {
+		widths[i] *= pdfContentWidth / total
+	}
+
+	d.y -= 6
+	header, headerLines := tableLines(t[0], widths, pdfBold)
+	headerHeight := float64(headerLines)*pdfTableLeading + 2*pdfCellPadding
+	// Lines of a row which fit on a page below the header
+	pageLines := int((pdfPageHeight - 2*pdfMargin - 10 - headerHeight - 2*pdfCellPadding) / pdfTableLeading)
+	d.ensure(headerHeight + pdfTableLeading + 2*pdfCellPadding)
+	d.tableRow(header, 0, headerLines, widths, pdfBold, pdfWhite, pdfBlue)
+	for i, row := range t[1:] {
+		fill := pdfWhite
+		if i%2 == 1 {
+			fill = pdfStripe
+		}
+		cells, lines := tableLines(row, widths, pdfRegular)
+		fresh := false
+		for from := 0; from < lines; {
+			// A row which fits on a page is moved to the next one, a taller
+			// row is split, the header being repeated on every page
+			fit := int((d.y - pdfMargin - 10 - 2*pdfCellPadding) / pdfTableLeading)
+			if !fresh && (fit < 1 || (from == 0 && fit < lines && lines <= pageLines)) {
+				d.newPage()
+				d.tableRow(header, 0, headerLines, widths, pdfBold, pdfWhite, pdfBlue)
+				fresh = true
+				continue
+			}
+			to := min(lines, from+max(fit, 1))
+			d.tableRow(cells, from, to, widths, pdfRegular, pdfInk, fill)
+			from, fresh = to, false
+		}
+	}
+	d.y -= 8
+}
+
+// tableLines wraps the cells of a row on the column widths and returns them
+// with the number of lines of the row.
+func tableLines(row []string, widths []float64, font pdfFont) ([][]string, int) {
+	cells := make([][]string, len(widths))
+	lines := 1
+	for i := range widths {
+		cell := ""
+		if i < len(row) {
+			cell = row[i]
+		}
+		cells[i] = wrapText(cell, font, pdfTableSize, widths[i]-2*pdfCellPadding)
+		lines = max(lines, len(cells[i]))
+	}
+	return cells, lines
+}
+
+// tableRow draws the lines [from, to) of the wrapped cells of a row, on a
+// fill colour (white for none).
+func (d *pdfDoc) tableRow(cells [][]string, from, to int, widths []float64, font pdfFont, ink, fill color.RGBA) {
+	height := float64(to-from)*pdfTableLeading + 2*pdfCellPadding
+	if fill != pdfWhite {
+		d.rect(pdfMargin, d.y-height, pdfContentWidth, height, fill)
+	}
+	x := pdfMargin
+	for i, cell := range cells {
+		for j := from; j < min(to, len(cell)); j++ {
+			d.text(x+pdfCellPadding, d.y-pdfCellPadding-float64(j-from+1)*pdfTableLeading+2.5, cell[j], font, pdfTableSize, ink)
+		}
+		x += widths[i]
+	}
+	d.y -= height
+	d.rect(pdfMargin, d.y, pdfContentWidth, 0.5, pdfBorder)
+}
+
+// chart draws the scene of c as vector graphics, scaled to the content
+// width, with its title above.
+func (d *pdfDoc) chart(c chart) {
+	scene := c.scene()
+	scale := min(0.75, pdfContentWidth/scene.Width)
+	d.ensure(24 + scene.Height*scale)
+	d.y -= 18
+	d.text(pdfMargin, d.y, c.Title, pdfBold, pdfTextSize, pdfInk)
+	d.y -= 6
+
+	left := pdfMargin + (pdfContentWidth-scene.Width*scale)/2
+	top := d.y
+	point := func(p chartPoint) string {
+		return pdfNum(left+p.X*scale) + " " + pdfNum(top-p.Y*scale)
+	}
+	for _, shape := range scene.Shapes {
+		switch {
+		case shape.Text != "":
+			font := pdfRegular
+			if shape.Bold {
+				font = pdfBold
+			}
+			size := shape.Size * scale
+			x := left + shape.Points[0].X*scale
+			switch shape.Anchor {
+			case anchorMiddle:
+				x -= textWidth(shape.Text, font, size) / 2
+			case anchorEnd:
+				x -= textWidth(shape.Text, font, size)
+			}
+			d.text(x, top-shape.Points[0].Y*scale, shape.Text, font, size, shape.Colour)
+		case shape.Width > 0:
+			fmt.Fprintf(d.page, "q 1 j %s RG %s w %s m", pdfColour(shape.Colour), pdfNum(shape.Width*scale), point(shape.Points[0]))
+			for _, p := range shape.Points[1:] {
+				fmt.Fprintf(d.page, " %s l", point(p))
+			}
+			d.page.WriteString(" S Q\n")
+		default:
+			fmt.Fprintf(d.page, "%s rg %s m", pdfColour(shape.Colour), point(shape.Points[0]))
+			for _, p := range shape.Points[1:] {
+				fmt.Fprintf(d.page, " %s l", point(p))
+			}
+			d.page.WriteString(" h f\n")
+		}
+	}
+	d.y -= scene.Height*scale + 10
+}
+
+// text draws s with its baseline at (x, y).
+func (d *pdfDoc) text(x, y float64, s string, font pdfFont, size float64, c color.RGBA) {
+	fmt.Fprintf(d.page, "BT /F%d %s Tf %s rg %s %s Td (%s) Tj ET\n", font+1, pdfNum(size), pdfColour(c), pdfNum(x), pdfNum(y), pdfString(s))
+}
+
+// centredText draws s centred on the page with its baseline at y.
+func (d *pdfDoc) centredText(y float64, s string, font pdfFont, size float64, c color.RGBA) {
+	d.text((pdfPageWidth-textWidth(s, font, size))/2, y, s, font, size, c)
+}
+
+// rect fills a rectangle, (x, y) being its bottom left corner.
+func (d *pdfDoc) rect(x, y, width, height float64, c color.RGBA) {
+	fmt.Fprintf(d.page, "%s rg %s %s %s %s re f\n", pdfColour(c), pdfNum(x), pdfNum(y), pdfNum(width), pdfNum(height))
+}
+
+// addImage decodes a PNG image and returns its index in d.images.
+func (d *pdfDoc) addImage(raw []byte) (int, error) {
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return 0, err
+	}
+	bounds := img.Bounds()
+	decoded := pdfImage{Width: bounds.Dx(), Height: bounds.Dy()}
+	alpha := make([]byte, 0, decoded.Width*decoded.Height)
+	opaque := true
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			decoded.RGB = append(decoded.RGB, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			opaque = opaque && c.A == 0xff
+		}
+	}
+	if !opaque {
+		decoded.Alpha = alpha
+	}
+	d.images = append(d.images, decoded)
+	return len(d.images) - 1, nil
+}
+
+// save writes the pages, fonts and images to path, adding the footer
+// (report title and page number) to every page.
+func (d *pdfDoc) save(path string) error {
+	for i, page := range d.pages {
+		d.page = page
+		d.text(pdfMargin, pdfMargin-20, d.title, pdfRegular, 8, pdfGrey)
+		number := fmt.Sprintf("%d / %d", i+1, len(d.pages))
+		d.text(pdfPageWidth-pdfMargin-textWidth(number, pdfRegular, 8), pdfMargin-20, number, pdfRegular, 8, pdfGrey)
+	}
+
+	// Objects 1 and 2 are the catalog and the page tree, written last
+	objects := make([][]byte, 2)
+	add := func(object []byte) int {
+		objects = append(objects, object)
+		return len(objects)
+	}
+
+	var fonts strings.Builder
+	for i, name := range pdfFontNames {
+		id := add([]byte(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name)))
+		fmt.Fprintf(&fonts, "/F%d %d 0 R ", i+1, id)
+	}
+
+	var xobjects strings.Builder
+	for i, img := range d.images {
+		mask := ""
+		if img.Alpha != nil {
+			id, err := addStream(add, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8", img.Width, img.Height), img.Alpha)
+			if err != nil {
+				return err
+			}
+			mask = fmt.Sprintf(" /SMask %d 0 R", id)
+		}
+		id, err := addStream(add, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8%s", img.Width, img.Height, mask), img.RGB)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&xobjects, "/Im%d %d 0 R ", i+1, id)
+	}
+
+	var kids strings.Builder
+	for _, page := range d.pages {
+		content, err := addStream(add, "", page.Bytes())
+		if err != nil {
+			return err
+		}
+		id := add([]byte(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s>> /XObject << %s>> >> /Contents %d 0 R >>",
+			pdfNum(pdfPageWidth), pdfNum(pdfPageHeight), fonts.String(), xobjects.String(), content)))
+		fmt.Fprintf(&kids, "%d 0 R ", id)
+	}
+	objects[0] = []byte("<< /Type /Catalog /Pages 2 0 R >>")
+	objects[1] = []byte(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids.String(), len(d.pages)))
+	info := add([]byte(fmt.Sprintf("<< /Title (%s) /Producer (PassTek) >>", pdfString(d.title))))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		buf.Write(object)
+		buf.WriteString("\nendobj\n")
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, info, xref)
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// addStream adds a Flate-compressed stream object with the given dictionary
+// entries and returns its object number.
+func addStream(add func([]byte) int, dict string, data []byte) (int, error) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	var object bytes.Buffer
+	fmt.Fprintf(&object, "<< %s /Length %d /Filter /FlateDecode >>\nstream\n", dict, compressed.Len())
+	object.Write(compressed.Bytes())
+	object.WriteString("\nendstream")
+	return add(object.Bytes()), nil
+}
+
+// wrapText splits s into lines no wider than width.
+func wrapText(s string, font pdfFont, size, width float64) []string {
+	var words []pdfWord
+	for _, word := range strings.Fields(s) {
+		words = append(words, pdfWord{Text: word, Font: font, Space: true})
+	}
+	var lines []string
+	for _, line := range wrapWords(words, size, width) {
+		texts := make([]string, len(line))
+		for i, word := range line {
+			texts[i] = word.Text
+		}
+		lines = append(lines, strings.Join(texts, " "))
+	}
+	if len(lines) == 0 {
+		return []string{""}
+	}
+	return lines
+}
+
+// wrapWords fills lines no wider than width with words. A word wider than
+// a line is cut.
+func wrapWords(words []pdfWord, size, width float64) [][]pdfWord {
+	var lines [][]pdfWord
+	var line []pdfWord
+	var lineWidth float64
+	for _, word := range words {
+		for textWidth(word.Text, word.Font, size) > width && len([]rune(word.Text)) > 1 {
+			// Cut the longest prefix that fits
+			runes := []rune(word.Text)
+			n := len(runes) - 1
+			for n > 1 && textWidth(string(runes[:n]), word.Font, size) > width {
+				n--
+			}
+			if len(line) > 0 {
+				lines = append(lines, line)
+			}
+			lines = append(lines, []pdfWord{{Text: string(runes[:n]), Font: word.Font}})
+			line, lineWidth = nil, 0
+			word.Text, word.Space = string(runes[n:]), false
+		}
+
+		wordWidth := textWidth(word.Text, word.Font, size)
+		if len(line) > 0 && word.Space {
+			wordWidth += textWidth(" ", word.Font, size)
+		}
+		if len(line) > 0 && lineWidth+wordWidth > width {
+			lines = append(lines, line)
+			line, lineWidth = nil, 0
+			wordWidth = textWidth(word.Text, word.Font, size)
+		}
+		line = append(line, word)
+		lineWidth += wordWidth
+	}
+	if len(line) > 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// textWidth returns the width of s in points. Accented letters are as wide
+// as their base letter.
+func textWidth(s string, font pdfFont, size float64) float64 {
+	widths := &helveticaWidths
+	if font == pdfBold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, r := range stripAccents(s) {
+		if r >= 32 && r <= 126 {
+			total += widths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// pdfString encodes s in Windows-1252, the encoding of the fonts, and
+// escapes it for a PDF literal string. Characters out of the encoding become
+// "?".
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		c, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			c = '?'
+		}
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\r', '\n':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// pdfNum formats a coordinate or size with at most two decimals.
+func pdfNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// pdfColour formats c as the operands of the rg operator.
+func pdfColour(c color.RGBA) string {
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
@@ -0,0 +1,502 @@
+package export
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"password-analyzer/utils"
+)
+
+// reportTable is a table of a report section, its first row being the header.
+type reportTable [][]string
+
+// chartEntry is one slice or bar of a chart.
+type chartEntry struct {
+	Label string
+	Value float64
+}
+
+// chartSeries is one line of a line chart, a value per category.
+type chartSeries struct {
+	Label  string
+	Values []float64
+}
+
+// chartKind is the kind of a chart, see chart.scene.
+type chartKind int
+
+const (
+	chartPie   chartKind = iota // Share of each entry
+	chartGauge                  // Risk score of the only entry, from 0 to 100
+	chartLine                   // Series over the categories
+)
+
+// chart is the data of one chart of the HTML report. ID is the id of its
+// element in the HTML template.
+type chart struct {
+	ID         string
+	Title      string
+	Kind       chartKind
+	Entries    []chartEntry
+	Categories []string      // Line charts only
+	Series     []chartSeries // Line charts only
+}
+
+// empty reports whether c has nothing to draw.
+func (c chart) empty() bool {
+	return len(c.Entries) == 0 && len(c.Series) == 0
+}
+
+// reportSection is one section of the HTML report, for the exporters that
+// lay out their own document rather than printing the HTML one.
+type reportSection struct {
+	Title  string
+	Text   string // Localised HTML text, see parseHTMLText
+	Tables []reportTable
+	Charts []chart
+	Sub    []reportSection // Sub-sections, e.g. one per domain
+}
+
+// reportSections returns the sections of the HTML report in the same order,
+// with the same conditions, tables and charts.
+func reportSections(data utils.Data) []reportSection {
+	stats, labels := data.Stats, data.Labels
+	html := labels.Html
+	var sections []reportSection
+
+	sections = append(sections, reportSection{
+		Title: string(html.Summary.Title),
+		Text:  string(html.Summary.Text),
+		Tables: []reportTable{{
+			{labels.Privileged.Figure, labels.Privileged.Value},
+			{labels.Risk.Title, fmt.Sprintf("%s (%.2f / 100)", stats.Risk, stats.GlobalPercent)},
+		}},
+		Charts: []chart{gaugeChart(stats, labels)},
+	})
+
+	if trend := stats.Trend; trend != nil && len(trend.Metrics) > 0 {
+		section := reportSection{Title: string(html.Trend.Title), Text: string(html.Trend.Text), Tables: []reportTable{trendTable(*trend, labels)}}
+		section.Charts = append(section.Charts, trendChart("chart-trend-global", labels.Trend.Global, "global", *trend, labels))
+		if trend.Has("length") {
+			section.Charts = append(section.Charts,
+				trendChart("chart-trend-length", labels.Trend.Length, "length", *trend, labels),
+				trendChart("chart-trend-complexity", labels.Trend.Complexity, "complexity", *trend, labels),
+			)
+		}
+		sections = append(sections, section)
+	}
+
+	if p := stats.Privileged; p != nil {
+		members := reportTable{{labels.Privileged.Account, labels.Privileged.Groups, labels.Privileged.Password}}
+		for _, account := range p.Accounts {
+			members = append(members, []string{account.Name(), strings.Join(account.Groups, ", "), crackedPassword(account.Cracked, account.Password)})
+		}
+		sections = append(sections, reportSection{
+			Title:  string(html.Privileged.Title),
+			Text:   string(html.Privileged.Text),
+			Tables: []reportTable{keyFiguresTable(*p, labels), members},
+		})
+	}
+
+	if len(stats.Classes) > 1 {
+		sections = append(sections, reportSection{
+			Title:  string(html.Classes.Title),
+			Text:   string(html.Classes.Text),
+			Tables: []reportTable{breakdownTable(labels.Classes.Class, stats.Classes, utils.ClassLabel, labels)},
+		})
+	}
+
+	if !stats.HashOnly {
+		sections = append(sections,
+			reportSection{Title: string(html.Length.Title), Text: string(html.Length.Text), Charts: []chart{lengthChart(stats, labels)}},
+			reportSection{Title: string(html.Complexity.Title), Text: string(html.Complexity.Text), Charts: []chart{complexityChart(stats, labels)}},
+			reportSection{Title: string(html.Occurrences.Title), Text: string(html.Occurrences.Text), Charts: []chart{topChart("chart-top-passwords", labels.Occurrences.Title, stats.TokenCount, stats.Top)}},
+		)
+	}
+	sections = append(sections, reportSection{Title: string(html.Reuse.Title), Text: string(html.Reuse.Text), Charts: []chart{reuseChart(stats, labels)}})
+	if !stats.HashOnly {
+		sections = append(sections,
+			reportSection{Title: string(html.Mostreuse.Title), Text: string(html.Mostreuse.Text), Charts: []chart{topChart("chart-mostreused", labels.Mostreuse.Title, stats.Mostreuse, stats.Top)}},
+			reportSection{Title: string(html.Patterns.Title), Text: string(html.Patterns.Text), Charts: []chart{topChart("chart-patterns", labels.Pattern.Title, stats.Patterns, stats.Top)}},
+		)
+	}
+
+	if len(stats.Clusters) > 0 {
+		sections = append(sections, reportSection{
+			Title:  string(html.Clusters.Title),
+			Text:   string(html.Clusters.Text),
+			Tables: []reportTable{clustersTable(stats.Clusters[:min(stats.Top, len(stats.Clusters))], labels)},
+		})
+	}
+
+	if stats.Age.Known > 0 {
+		sections = append(sections, reportSection{Title: string(html.Age.Title), Text: string(html.Age.Text), Charts: []chart{ageChart(stats, labels)}})
+	}
+
+	if len(stats.PreWin2000) > 0 {
+		sections = append(sections, reportSection{
+			Title:  string(html.PreWin2000.Title),
+			Text:   string(html.PreWin2000.Text),
+			Tables: []reportTable{listTable(labels.PreWin2000.A1, stats.PreWin2000)},
+		})
+	}
+
+	if b := stats.Builtin; len(b.GuestEmpty)+len(b.AdminReused)+len(b.KrbtgtReused) > 0 {
+		sections = append(sections, reportSection{Title: string(html.Builtin.Title), Text: string(html.Builtin.Text), Tables: []reportTable{builtinTable(b, labels)}})
+	}
+
+	if len(stats.Domains) > 0 {
+		section := reportSection{
+			Title:  string(html.Domains.Title),
+			Text:   string(html.Domains.Text),
+			Tables: []reportTable{breakdownTable(labels.Domains.Domain, stats.Domains, utils.DomainLabel, labels)},
+		}
+		for _, domain := range stats.Domains {
+			sub := reportSection{Title: utils.DomainLabel(labels, domain.Name), Tables: []reportTable{keyFiguresTable(domain.Stats, labels)}}
+			if !domain.Stats.HashOnly && len(domain.Stats.Mostreuse) > 0 {
+				sub.Tables = append(sub.Tables, topTable(labels.Mostreuse.A1, labels.Mostreuse.B1, domain.Stats.Mostreuse, stats.Top))
+			}
+			section.Sub = append(section.Sub, sub)
+		}
+		sections = append(sections, section)
+	}
+
+	if len(stats.CrossDomain) > 0 {
+		sections = append(sections, reportSection{
+			Title:  string(html.CrossDomain.Title),
+			Text:   string(html.CrossDomain.Text),
+			Tables: []reportTable{clustersTable(stats.CrossDomain, labels)},
+		})
+	}
+
+	if len(stats.AdminLinks.Shared) > 0 {
+		sections = append(sections, reportSection{Title: string(html.AdminLinks.Title), Text: string(html.AdminLinks.Text), Tables: []reportTable{adminLinksTable(stats.AdminLinks, labels)}})
+	}
+
+	if u := stats.Unchanged; u.Enabled {
+		section := reportSection{Title: string(html.Unchanged.Title), Text: string(html.Unchanged.Text), Tables: []reportTable{unchangedTable(stats, labels)}}
+		if len(u.Accounts) > 0 {
+			section.Tables = append(section.Tables, listTable(labels.Unchanged.A1, u.Accounts))
+		}
+		sections = append(sections, section)
+	}
+
+	if len(stats.Builtin.RIDRanges) > 0 {
+		sections = append(sections, reportSection{Title: string(html.RIDRanges.Title), Text: string(html.RIDRanges.Text), Tables: []reportTable{ridRangesTable(stats.Builtin.RIDRanges, labels)}})
+	}
+
+	if h := stats.History; h.Accounts > 0 {
+		sections = append(sections, reportSection{Title: string(html.History.Title), Text: string(html.History.Text), Tables: []reportTable{historyTable(h, labels)}})
+	}
+
+	if w := stats.Weighted; w.Enabled {
+		lengths := reportTable{
+			{labels.Length.A1, labels.Weighted.Unweighted, labels.Weighted.Weighted},
+			{labels.Length.Short, fmt.Sprint(utils.SumLengthRange(stats.Lengths, 0, 7)), fmt.Sprint(utils.SumLengthRange(w.Lengths, 0, 7))},
+			{labels.Length.Exact8, fmt.Sprint(stats.Lengths[8]), fmt.Sprint(w.Lengths[8])},
+			{labels.Length.Exact9, fmt.Sprint(stats.Lengths[9]), fmt.Sprint(w.Lengths[9])},
+			{labels.Length.Exact10, fmt.Sprint(stats.Lengths[10]), fmt.Sprint(w.Lengths[10])},
+			{labels.Length.Long, fmt.Sprint(utils.SumLengthRange(stats.Lengths, 11, 100)), fmt.Sprint(utils.SumLengthRange(w.Lengths, 11, 100))},
+		}
+		complexity := reportTable{
+			{labels.Complexity.A1, labels.Weighted.Unweighted, labels.Weighted.Weighted},
+			{labels.Complexity.One, fmt.Sprint(stats.Complexity[1]), fmt.Sprint(w.Complexity[1])},
+			{labels.Complexity.Two, fmt.Sprint(stats.Complexity[2]), fmt.Sprint(w.Complexity[2])},
+			{labels.Complexity.Three, fmt.Sprint(stats.Complexity[3]), fmt.Sprint(w.Complexity[3])},
+			{labels.Complexity.Four, fmt.Sprint(stats.Complexity[4]), fmt.Sprint(w.Complexity[4])},
+		}
+		tokens := reportTable{{labels.Occurrences.A1, labels.Weighted.Unweighted, labels.Weighted.Weighted}}
+		for _, e := range topEntries(stats.TokenCount, stats.Top) {
+			tokens = append(tokens, []string{e.Key, fmt.Sprint(e.Value), fmt.Sprint(w.TokenCount[e.Key])})
+		}
+		patterns := reportTable{{labels.Pattern.A1, labels.Weighted.Unweighted, labels.Weighted.Weighted}}
+		for _, e := range topEntries(stats.Patterns, stats.Top) {
+			patterns = append(patterns, []string{e.Key, fmt.Sprint(e.Value), fmt.Sprint(w.Patterns[e.Key])})
+		}
+		text := string(html.Weighted.Text)
+		if w.PerAccount {
+			text += "<br>" + template.HTMLEscapeString(labels.Weighted.Identical)
+		}
+		sections = append(sections, reportSection{
+			Title:  string(html.Weighted.Title),
+			Text:   text,
+			Tables: []reportTable{lengths, complexity, tokens, patterns},
+		})
+	}
+
+	return append(sections, reportSection{Title: string(html.Remediation.Title), Text: string(html.Remediation.Text)})
+}
+
+// keyFiguresTable returns the key figures of a subset of accounts, as in the
+// domain panels of the HTML report.
+func keyFiguresTable(s utils.Stats, labels utils.Labels) reportTable {
+	table := reportTable{
+		{labels.Privileged.Figure, labels.Privileged.Value},
+		{labels.Hash.TotalNTLM, fmt.Sprint(s.Hashes.TotalNTLMHashes)},
+	}
+	if !s.HashOnly {
+		table = append(table,
+			[]string{labels.Hash.Cracked, fmt.Sprintf("%d (%.1f%%)", s.CrackedCount, utils.Percent(s.CrackedCount, s.Hashes.TotalNTLMHashes))},
+			[]string{labels.Length.Short, fmt.Sprint(utils.SumLengthRange(s.Lengths, 0, 7))},
+			[]string{labels.Length.Exact8, fmt.Sprint(s.Lengths[8])},
+			[]string{labels.Length.Exact9, fmt.Sprint(s.Lengths[9])},
+			[]string{labels.Length.Exact10, fmt.Sprint(s.Lengths[10])},
+			[]string{labels.Length.Long, fmt.Sprint(utils.SumLengthRange(s.Lengths, 11, 100))},
+			[]string{labels.Complexity.One, fmt.Sprint(s.Complexity[1])},
+			[]string{labels.Complexity.Two, fmt.Sprint(s.Complexity[2])},
+			[]string{labels.Complexity.Three, fmt.Sprint(s.Complexity[3])},
+			[]string{labels.Complexity.Four, fmt.Sprint(s.Complexity[4])},
+		)
+	}
+	return append(table,
+		[]string{labels.Hash.Reused, fmt.Sprint(s.Hashes.ReusedNTLMHashes)},
+		[]string{labels.Hash.LM, fmt.Sprint(s.Hashes.IsLM)},
+		[]string{labels.Hash.EmptyNTLM, fmt.Sprint(s.Hashes.EmptyNTLMHashes)},
+		[]string{labels.Hash.UserEqualHash, fmt.Sprint(len(s.Hashes.UserEqualHash))},
+		[]string{labels.History.Title, fmt.Sprint(len(s.History.ReusedCurrent) + len(s.History.Cycling) + len(s.History.Incremental))},
+		[]string{labels.Age.Older, fmt.Sprint(len(s.Age.Older))},
+		[]string{labels.Risk.Title, s.Risk},
+	)
+}
+
+// trendTable returns the key metrics of every audit and their evolution.
+func trendTable(trend utils.Trend, labels utils.Labels) reportTable {
+	table := reportTable{append(append([]string{labels.Trend.Metric}, trend.Dates...), labels.Trend.Delta)}
+	for _, metric := range trend.Metrics {
+		row := []string{utils.TrendLabel(labels, metric.Key)}
+		for _, value := range metric.Values {
+			row = append(row, fmt.Sprintf("%.1f", value))
+		}
+		table = append(table, append(row, trendArrow(metric)))
+	}
+	return table
+}
+
+// builtinTable returns one row per finding on a well-known account.
+func builtinTable(b utils.BuiltinStats, labels utils.Labels) reportTable {
+	table := reportTable{{labels.Builtin.Finding, labels.Builtin.Account, labels.Builtin.Detail}}
+	for _, account := range b.GuestEmpty {
+		table = append(table, []string{labels.Builtin.GuestEmpty, account, ""})
+	}
+	for _, shared := range b.AdminReused {
+		table = append(table, []string{labels.Builtin.AdminReused, shared.Builtin, strings.Join(shared.Accounts, ", ")})
+	}
+	for _, shared := range b.KrbtgtReused {
+		table = append(table, []string{labels.Builtin.KrbtgtReused, shared.Builtin, strings.Join(shared.Accounts, ", ")})
+	}
+	return table
+}
+
+// adminLinksTable returns one row per personal and administration account
+// pair sharing a password.
+func adminLinksTable(links utils.AdminLinks, labels utils.Labels) reportTable {
+	table := reportTable{{labels.AdminLinks.Personal, labels.AdminLinks.Admin, labels.AdminLinks.Detail}}
+	for _, pair := range links.Shared {
+		detail := labels.AdminLinks.SameHash
+		if !pair.SameHash {
+			detail = pair.PersonalPassword + " → " + pair.AdminPassword
+		}
+		table = append(table, []string{pair.Personal, pair.Admin, detail})
+	}
+	return table
+}
+
+// unchangedTable returns the figures of the passwords unchanged since the
+// previous audit.
+func unchangedTable(stats utils.Stats, labels utils.Labels) reportTable {
+	u := stats.Unchanged
+	table := reportTable{
+		{labels.Unchanged.Matched, labels.Unchanged.Unchanged},
+		{fmt.Sprint(u.Matched), fmt.Sprintf("%d (%.1f%%)", len(u.Accounts), utils.Percent(len(u.Accounts), u.Matched))},
+	}
+	if !stats.HashOnly {
+		table[0] = append(table[0], labels.Unchanged.Cracked)
+		table[1] = append(table[1], fmt.Sprint(u.Cracked))
+	}
+	return table
+}
+
+// ridRangesTable returns the crack rate of every RID range.
+func ridRangesTable(ranges []utils.RIDRange, labels utils.Labels) reportTable {
+	table := reportTable{{labels.RIDRanges.Range, labels.RIDRanges.Accounts, labels.RIDRanges.Cracked, labels.RIDRanges.Rate}}
+	for _, r := range ranges {
+		table = append(table, []string{r.Name(), fmt.Sprint(r.Accounts), fmt.Sprint(r.Cracked), fmt.Sprintf("%.1f%%", utils.Percent(r.Cracked, r.Accounts))})
+	}
+	return table
+}
+
+// historyTable returns one row per password history finding.
+func historyTable(h utils.HistoryStats, labels utils.Labels) reportTable {
+	table := reportTable{{labels.History.Finding, labels.History.Account, labels.History.Detail}}
+	for _, account := range h.ReusedCurrent {
+		table = append(table, []string{labels.History.ReusedCurrent, account, ""})
+	}
+	for _, account := range h.Cycling {
+		table = append(table, []string{labels.History.Cycling, account, ""})
+	}
+	for _, change := range h.Incremental {
+		table = append(table, []string{labels.History.Incremental, change.Account, change.Previous + " → " + change.Current})
+	}
+	return table
+}
+
+// breakdownTable returns one row of key figures per subset of accounts.
+func breakdownTable(column string, breakdown []utils.Breakdown, name func(utils.Labels, string) string, labels utils.Labels) reportTable {
+	table := reportTable{{column, labels.Hash.TotalNTLM, labels.Hash.Cracked, labels.Hash.Reused, labels.Hash.LM, labels.Hash.EmptyNTLM, labels.Hash.UserEqualHash, labels.Risk.Title}}
+	for _, b := range breakdown {
+		s := b.Stats
+		cracked := "-"
+		if !s.HashOnly {
+			cracked = fmt.Sprintf("%d (%.1f%%)", s.CrackedCount, utils.Percent(s.CrackedCount, s.Hashes.TotalNTLMHashes))
+		}
+		table = append(table, []string{
+			name(labels, b.Name),
+			fmt.Sprint(s.Hashes.TotalNTLMHashes),
+			cracked,
+			fmt.Sprint(s.Hashes.ReusedNTLMHashes),
+			fmt.Sprint(s.Hashes.IsLM),
+			fmt.Sprint(s.Hashes.EmptyNTLMHashes),
+			fmt.Sprint(len(s.Hashes.UserEqualHash)),
+			s.Risk,
+		})
+	}
+	return table
+}
+
+// clustersTable returns one row per reuse cluster.
+func clustersTable(clusters []utils.ReuseCluster, labels utils.Labels) reportTable {
+	table := reportTable{{labels.Clusters.Group, labels.Clusters.Size, labels.Clusters.Password, labels.Clusters.Accounts}}
+	for _, c := range clusters {
+		table = append(table, []string{fmt.Sprintf("#%d", c.Group), fmt.Sprint(c.Size), crackedPassword(c.Cracked, c.Password), strings.Join(c.Accounts, ", ")})
+	}
+	return table
+}
+
+// listTable returns a single-column table.
+func listTable(column string, values []string) reportTable {
+	table := reportTable{{column}}
+	for _, value := range values {
+		table = append(table, []string{value})
+	}
+	return table
+}
+
+// topTable returns the top entries of m, largest first.
+func topTable(key, value string, m map[string]int, top int) reportTable {
+	table := reportTable{{key, value}}
+	for _, e := range topEntries(m, top) {
+		table = append(table, []string{e.Key, fmt.Sprint(e.Value)})
+	}
+	return table
+}
+
+// topEntries returns the top entries of m, largest first.
+func topEntries(m map[string]int, top int) []utils.Entry {
+	entries := utils.SortMapByValueDesc(m)
+	return entries[:min(top, len(entries))]
+}
+
+// crackedPassword returns password, or "-" when it was not cracked.
+func crackedPassword(cracked bool, password string) string {
+	if !cracked {
+		return "-"
+	}
+	return password
+}
+
+// reportCharts returns every chart of the report, in order.
+func reportCharts(data utils.Data) []chart {
+	var charts []chart
+	var collect func(sections []reportSection)
+	collect = func(sections []reportSection) {
+		for _, section := range sections {
+			for _, c := range section.Charts {
+				if !c.empty() {
+					charts = append(charts, c)
+				}
+			}
+			collect(section.Sub)
+		}
+	}
+	collect(reportSections(data))
+	return charts
+}
+
+// gaugeChart returns the risk gauge of the summary.
+func gaugeChart(stats utils.Stats, labels utils.Labels) chart {
+	return chart{ID: "summary-gauge", Title: labels.Risk.Title, Kind: chartGauge, Entries: []chartEntry{{stats.Risk, stats.GlobalPercent}}}
+}
+
+// trendChart returns the line chart of the trend metrics of group, one point
+// per audit.
+func trendChart(id, title, group string, trend utils.Trend, labels utils.Labels) chart {
+	c := chart{ID: id, Title: title, Kind: chartLine, Categories: trend.Dates}
+	for _, metric := range trend.Metrics {
+		if metric.Group == group {
+			c.Series = append(c.Series, chartSeries{utils.TrendLabel(labels, metric.Key), metric.Values})
+		}
+	}
+	return c
+}
+
+// lengthChart returns the password length chart, empty buckets left out.
+func lengthChart(stats utils.Stats, labels utils.Labels) chart {
+	return nonZeroChart("chart-length", labels.Length.Title, []chartEntry{
+		{labels.Length.Short, float64(utils.SumLengthRange(stats.Lengths, 0, 7))},
+		{labels.Length.Exact8, float64(stats.Lengths[8])},
+		{labels.Length.Exact9, float64(stats.Lengths[9])},
+		{labels.Length.Exact10, float64(stats.Lengths[10])},
+		{labels.Length.Long, float64(utils.SumLengthRange(stats.Lengths, 11, 100))},
+	})
+}
+
+// complexityChart returns the password complexity chart.
+func complexityChart(stats utils.Stats, labels utils.Labels) chart {
+	return nonZeroChart("chart-complexity", labels.Complexity.Title, []chartEntry{
+		{labels.Complexity.One, float64(stats.Complexity[1])},
+		{labels.Complexity.Two, float64(stats.Complexity[2])},
+		{labels.Complexity.Three, float64(stats.Complexity[3])},
+		{labels.Complexity.Four, float64(stats.Complexity[4])},
+	})
+}
+
+// reuseChart returns the reused and unique NT hashes chart.
+func reuseChart(stats utils.Stats, labels utils.Labels) chart {
+	return chart{ID: "chart-reused", Title: labels.Reuse.Title, Entries: []chartEntry{
+		{labels.Reuse.Short, float64(stats.Hashes.ReusedNTLMHashes)},
+		{labels.Reuse.Unique, float64(stats.Hashes.TotalNTLMHashes - stats.Hashes.ReusedNTLMHashes)},
+	}}
+}
+
+// ageChart returns the password age chart.
+func ageChart(stats utils.Stats, labels utils.Labels) chart {
+	names := []string{labels.Age.Under90, labels.Age.Under180, labels.Age.Under365, labels.Age.Under730, labels.Age.Under1825, labels.Age.Over1825}
+	var entries []chartEntry
+	for i, name := range names {
+		if i < len(stats.Age.Buckets) {
+			entries = append(entries, chartEntry{name, float64(stats.Age.Buckets[i])})
+		}
+	}
+	return nonZeroChart("chart-age", labels.Age.Title, entries)
+}
+
+// topChart returns the chart of the top entries of m.
+func topChart(id, title string, m map[string]int, top int) chart {
+	c := chart{ID: id, Title: title}
+	for _, e := range topEntries(m, top) {
+		c.Entries = append(c.Entries, chartEntry{e.Key, float64(e.Value)})
+	}
+	return c
+}
+
+// nonZeroChart returns a chart of the non-zero entries.
+func nonZeroChart(id, title string, entries []chartEntry) chart {
+	c := chart{ID: id, Title: title}
+	for _, e := range entries {
+		if e.Value > 0 {
+			c.Entries = append(c.Entries, e)
+		}
+	}
+	return c
+}
@@ -0,0 +1,131 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"password-analyzer/utils"
+)
+
+// resetFindings lists the findings whose accounts must change their password,
+// in script order.
+var resetFindings = []string{
+	utils.FindingUserAsPassword,
+	utils.FindingLM,
+	utils.FindingReused,
+	utils.FindingCracked,
+}
+
+// resetHeader is the parameter block and helper function of the reset
+// script. Set-ADUser is sent to the domain of each account.
+const resetHeader = `[CmdletBinding()]
+param(
+    # Only print the accounts that would be changed
+    [switch]$DryRun
+)
+
+Import-Module ActiveDirectory
+
+function Set-ChangeAtLogon {
+    param([string]$Domain, [string]$Identity)
+    if ($DryRun) {
+        Write-Host "[dry-run] $Domain\$Identity"
+        return
+    }
+    $params = @{ Identity = $Identity; ChangePasswordAtLogon = $true }
+    if ($Domain) {
+        $params.Server = $Domain
+    }
+    Set-ADUser @params
+    Write-Host "[+] $Domain\$Identity"
+}
+`
+
+// ToResetScript writes `reset-passwords.ps1` and `reset-accounts.txt` inside
+// outputDir: a PowerShell script setting "User must change password at next
+// logon" on the accounts that are cracked, reused, stored as LM or use their
+// username as password, grouped by finding, and the plain list of these
+// accounts. The script is meant to be reviewed and run by the client; it is
+// never executed by PassTek. Machine accounts are left out.
+func ToResetScript(findings []utils.Finding, outputDir string, labels utils.Labels) error {
+	script, err := os.Create(outputDir + "/reset-passwords.ps1")
+	if err != nil {
+		return err
+	}
+	defer script.Close()
+	list, err := os.Create(outputDir + "/reset-accounts.txt")
+	if err != nil {
+		return err
+	}
+	defer list.Close()
+
+	// Byte order mark, for Windows PowerShell to read localised comments as UTF-8
+	fmt.Fprint(script, "\ufeff")
+	fmt.Fprintln(script, "<#")
+	fmt.Fprintf(script, ".SYNOPSIS\n    %s\n", labels.Reset.Synopsis)
+	fmt.Fprintf(script, ".DESCRIPTION\n%s\n", indentLines(labels.Reset.Description, "    "))
+	fmt.Fprintf(script, "    PassTek - %s\n", time.Now().Format("2006-01-02 15:04"))
+	fmt.Fprintln(script, "#>")
+	fmt.Fprint(script, resetHeader)
+	fmt.Fprintln(script)
+	fmt.Fprintln(script, indentLines(labels.Reset.LMGuidance, "# "))
+
+	seen := make(map[string]bool)
+	for _, key := range resetFindings {
+		for _, finding := range findings {
+			if finding.Key == key {
+				writeResetFinding(script, list, finding, seen, labels)
+			}
+		}
+	}
+	return nil
+}
+
+// writeResetFinding writes the section of finding in the script and adds its
+// accounts to the plain list. Accounts already reset by a previous section
+// are only mentioned as a comment.
+func writeResetFinding(script, list io.Writer, finding utils.Finding, seen map[string]bool, labels utils.Labels) {
+	var accounts []utils.Account
+	for _, account := range finding.Accounts {
+		if !account.Machine() {
+			accounts = append(accounts, account)
+		}
+	}
+
+	fmt.Fprintf(script, "\n# ===== %s (%d) =====\n", labels.Reset.Names[finding.Key], len(accounts))
+	for _, account := range accounts {
+		name := strings.ToLower(account.Name())
+		if seen[name] {
+			fmt.Fprintf(script, "# %s : %s\n", account.Name(), labels.Reset.Already)
+			continue
+		}
+		seen[name] = true
+		fmt.Fprintf(script, "Set-ChangeAtLogon %s %s\n", psQuote(account.Domain), psQuote(account.Username))
+		fmt.Fprintln(list, account.Name())
+	}
+}
+
+// psQuote returns s as a single-quoted PowerShell string. PowerShell also
+// ends such strings with the typographic single quotes (U+2018 to U+201B),
+// which account names may contain, so every one of them is doubled too.
+func psQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\'', '\u2018', '\u2019', '\u201a', '\u201b':
+			b.WriteRune(r)
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// indentLines prefixes every line of text with prefix.
+func indentLines(text, prefix string) string {
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
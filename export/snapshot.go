@@ -0,0 +1,20 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+
+	"password-analyzer/utils"
+)
+
+// ToSnapshot writes `snapshot.json` inside outputDir, the statistics of the
+// run to be compared with the next audits (-compare or diff mode). The
+// snapshot only holds aggregate counters (see analysis.NewSnapshot); the file
+// is still only readable by its owner.
+func ToSnapshot(snapshot utils.Snapshot, outputDir string) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputDir+"/snapshot.json", data, 0600)
+}
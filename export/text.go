@@ -4,7 +4,10 @@ package export
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"unicode/utf8"
 
 	"password-analyzer/utils"
 )
@@ -21,6 +24,45 @@ func ToText(stats utils.Stats, outputDir string, top int, labels utils.Labels) e
 	}
 	defer f.Close()
 
+	writeStats(f, stats, top, labels)
+
+	// Comparison with previous audits (-compare)
+	if stats.Trend != nil {
+		WriteTrend(f, *stats.Trend, labels)
+	}
+
+	// Same report restricted to privileged accounts (-groups)
+	if stats.Privileged != nil {
+		fmt.Fprintf(f, "\n\n########## %s ##########\n", labels.Privileged.Title)
+		writePrivileged(f, stats.Privileged.Accounts, labels)
+		writeStats(f, *stats.Privileged, top, labels)
+	}
+
+	// Same report for every account class
+	if len(stats.Classes) > 1 {
+		fmt.Fprintf(f, "\n\n########## %s ##########\n", labels.Classes.Title)
+		writeBreakdown(f, labels.Classes.Class, stats.Classes, utils.ClassLabel, labels)
+		for _, class := range stats.Classes {
+			fmt.Fprintf(f, "\n\n########## %s : %s ##########\n", labels.Classes.Class, utils.ClassLabel(labels, class.Name))
+			writeStats(f, class.Stats, top, labels)
+		}
+	}
+
+	// Same report for every domain of a merged hash file
+	if len(stats.Domains) > 0 {
+		fmt.Fprintf(f, "\n\n########## %s ##########\n", labels.Domains.Title)
+		writeBreakdown(f, labels.Domains.Domain, stats.Domains, utils.DomainLabel, labels)
+		for _, domain := range stats.Domains {
+			fmt.Fprintf(f, "\n\n########## %s : %s ##########\n", labels.Domains.Domain, utils.DomainLabel(labels, domain.Name))
+			writeStats(f, domain.Stats, top, labels)
+		}
+	}
+	return nil
+}
+
+// writeStats writes every section of the text report for stats. It is
+// called for the whole hash file, then for each subset of accounts.
+func writeStats(f io.Writer, stats utils.Stats, top int, labels utils.Labels) {
 	// Hash analysis
 	if stats.Hashes.IsHash {
 		fmt.Fprintf(f, "\n=== %s ===\n", labels.Hash.Title)
@@ -32,11 +74,22 @@ func ToText(stats utils.Stats, outputDir string, top int, labels utils.Labels) e
 			labels.Hash.LM,
 			labels.Hash.EmptyNTLM,
 			labels.Hash.UserEqualHash,
+			labels.Hash.Disabled,
+			labels.Hash.DisabledCrack,
+			labels.Classes.Machines,
 		)
+		if stats.Privileged != nil {
+			hashWidth = max(hashWidth, len(labels.Privileged.Headline))
+		}
 
 		fmtStr := fmt.Sprintf("%%-%ds : %%d\n", hashWidth)
+		if p := stats.Privileged; p != nil && !p.HashOnly {
+			fmt.Fprintf(f, "%-*s : %d / %d (%.1f%%)\n", hashWidth, labels.Privileged.Headline, p.CrackedCount, p.Hashes.TotalNTLMHashes, utils.Percent(p.CrackedCount, p.Hashes.TotalNTLMHashes))
+		}
 		fmt.Fprintf(f, fmtStr, labels.Hash.TotalNTLM, stats.Hashes.TotalNTLMHashes)
-		fmt.Fprintf(f, fmtStr, labels.Hash.Cracked, stats.CrackedCount)
+		if !stats.HashOnly {
+			fmt.Fprintf(f, fmtStr, labels.Hash.Cracked, stats.CrackedCount)
+		}
 		fmt.Fprintf(f, fmtStr, labels.Hash.UniqueNTLM, stats.Hashes.UniqueNTLMHashes)
 		fmt.Fprintf(f, fmtStr, labels.Hash.Reused, stats.Hashes.ReusedNTLMHashes)
 		fmt.Fprintf(f, fmtStr, labels.Hash.LM, stats.Hashes.IsLM)
@@ -44,8 +97,259 @@ func ToText(stats utils.Stats, outputDir string, top int, labels utils.Labels) e
 		if len(stats.Hashes.UserEqualHash) > 0 {
 			fmt.Fprintf(f, fmtStr, labels.Hash.UserEqualHash, len(stats.Hashes.UserEqualHash))
 		}
+		if stats.Hashes.Disabled > 0 {
+			note := ""
+			if !stats.Hashes.DisabledIncluded {
+				note = " (" + labels.Hash.Excluded + ")"
+			}
+			fmt.Fprintf(f, "%-*s : %d%s\n", hashWidth, labels.Hash.Disabled, stats.Hashes.Disabled, note)
+			fmt.Fprintf(f, fmtStr, labels.Hash.DisabledCrack, stats.Hashes.DisabledCracked)
+		}
+		if stats.Hashes.Machines > 0 {
+			note := ""
+			if !stats.Hashes.MachinesIncluded {
+				note = " (" + labels.Hash.Excluded + ")"
+			}
+			fmt.Fprintf(f, "%-*s : %d%s\n", hashWidth, labels.Classes.Machines, stats.Hashes.Machines, note)
+		}
+	}
+
+	// Cracked password analysis (skipped in hash-only mode)
+	if !stats.HashOnly {
+		writePasswordSections(f, stats, top, labels)
+	}
+
+	// Cracked accounts (requires a hash file)
+	if len(stats.Accounts) > 0 && !stats.HashOnly {
+		fmt.Fprintf(f, "\n=== %s ===\n", labels.Accounts.Title)
+		writeAccounts(f, stats.Accounts, labels)
+	}
+
+	// Accounts sharing an NT hash, largest cluster first
+	if len(stats.Clusters) > 0 {
+		fmt.Fprintf(f, "\n=== %s ===\n", labels.Clusters.Title)
+		writeClusters(f, stats.Clusters, labels)
+	}
+
+	// NT hashes shared across domains
+	if len(stats.CrossDomain) > 0 {
+		fmt.Fprintf(f, "\n=== %s ===\n", labels.Domains.CrossTitle)
+		writeClusters(f, stats.CrossDomain, labels)
+	}
+
+	// Previous passwords (secretsdump -history)
+	if stats.History.Accounts > 0 {
+		fmt.Fprintf(f, "\n=== %s ===\n", labels.History.Title)
+		writeHistory(f, stats.History, labels)
+	}
+
+	// Password age (secretsdump -pwd-last-set)
+	if stats.Age.Known > 0 || len(stats.Age.NeverSet) > 0 {
+		fmt.Fprintf(f, "\n=== %s ===\n", labels.Age.Title)
+		writeAge(f, stats.Age, labels)
+	}
+
+	// Computer accounts with their pre-Windows 2000 default password
+	if len(stats.PreWin2000) > 0 {
+		fmt.Fprintf(f, "\n=== %s === (%d)\n", labels.PreWin2000.Title, len(stats.PreWin2000))
+		for _, account := range stats.PreWin2000 {
+			fmt.Fprintln(f, account)
+		}
+	}
+
+	// Well-known accounts (RID 500, 501, 502)
+	if b := stats.Builtin; len(b.GuestEmpty) > 0 || len(b.AdminReused) > 0 || len(b.KrbtgtReused) > 0 {
+		fmt.Fprintf(f, "\n=== %s ===\n", labels.Builtin.Title)
+		writeBuiltin(f, b, labels)
+	}
+
+	// Passwords unchanged since the previous audit
+	if u := stats.Unchanged; u.Enabled {
+		fmt.Fprintf(f, "\n=== %s ===\n", labels.Unchanged.Title)
+		width := utils.MaxLabelLength(labels.Unchanged.Matched, labels.Unchanged.Unchanged, labels.Unchanged.Cracked)
+		fmt.Fprintf(f, "%-*s : %d\n", width, labels.Unchanged.Matched, u.Matched)
+		fmt.Fprintf(f, "%-*s : %d (%.1f%%)\n", width, labels.Unchanged.Unchanged, len(u.Accounts), utils.Percent(len(u.Accounts), u.Matched))
+		if !stats.HashOnly {
+			fmt.Fprintf(f, "%-*s : %d\n", width, labels.Unchanged.Cracked, u.Cracked)
+		}
+		if len(u.Accounts) > 0 {
+			fmt.Fprintf(f, "\n-- %s --\n", labels.Unchanged.Unchanged)
+			for _, account := range u.Accounts {
+				fmt.Fprintln(f, account)
+			}
+		}
+	}
+
+	// Personal and administration accounts sharing a password
+	if stats.AdminLinks.Pairs > 0 {
+		fmt.Fprintf(f, "\n=== %s ===\n", labels.AdminLinks.Title)
+		writeAdminLinks(f, stats.AdminLinks, labels)
+	}
+
+	// Crack rate of old and recent accounts
+	if len(stats.Builtin.RIDRanges) > 0 {
+		fmt.Fprintf(f, "\n=== %s ===\n", labels.RIDRanges.Title)
+		names := make([]string, len(stats.Builtin.RIDRanges))
+		for i, r := range stats.Builtin.RIDRanges {
+			names[i] = r.Name()
+		}
+		width := utils.MaxLabelLength(append(names, labels.RIDRanges.Range)...)
+		fmt.Fprintf(f, "%-*s : %s / %s (%s)\n", width, labels.RIDRanges.Range, labels.RIDRanges.Cracked, labels.RIDRanges.Accounts, labels.RIDRanges.Rate)
+		for i, r := range stats.Builtin.RIDRanges {
+			fmt.Fprintf(f, "%-*s : %d / %d (%.1f%%)\n", width, names[i], r.Cracked, r.Accounts, utils.Percent(r.Cracked, r.Accounts))
+		}
+	}
+
+	// Cracked passwords that could not be attributed to any account
+	if len(stats.Orphans) > 0 {
+		fmt.Fprintf(f, "\n=== %s === (%d)\n", labels.Orphans.Title, len(stats.Orphans))
+		for _, orphan := range stats.Orphans {
+			fmt.Fprintln(f, orphan)
+		}
+	}
+}
+
+// writeBreakdown prints one aligned row of key figures per subset of
+// accounts: accounts, cracked accounts and rate, reuse, LM, empty passwords,
+// username as password and risk. column heads the subset names, which are
+// displayed with name.
+func writeBreakdown(w io.Writer, column string, breakdown []utils.Breakdown, name func(utils.Labels, string) string, labels utils.Labels) {
+	header := []string{
+		column,
+		labels.Hash.TotalNTLM,
+		labels.Hash.Cracked,
+		labels.Hash.CrackedRate,
+		labels.Hash.Reused,
+		labels.Hash.LM,
+		labels.Hash.EmptyNTLM,
+		labels.Hash.UserEqualHash,
+		labels.Risk.Title,
+	}
+	rows := [][]string{header}
+	for _, b := range breakdown {
+		s := b.Stats
+		rows = append(rows, []string{
+			name(labels, b.Name),
+			fmt.Sprint(s.Hashes.TotalNTLMHashes),
+			fmt.Sprint(s.CrackedCount),
+			fmt.Sprintf("%.1f", utils.Percent(s.CrackedCount, s.Hashes.TotalNTLMHashes)),
+			fmt.Sprint(s.Hashes.ReusedNTLMHashes),
+			fmt.Sprint(s.Hashes.IsLM),
+			fmt.Sprint(s.Hashes.EmptyNTLMHashes),
+			fmt.Sprint(len(s.Hashes.UserEqualHash)),
+			s.Risk,
+		})
+	}
+	writeTable(w, rows)
+}
+
+// WriteTrend prints the key metrics of two or more audits, one column per
+// audit, and their evolution since the previous audit. It is used by the
+// text report (-compare) and by the diff mode.
+func WriteTrend(w io.Writer, trend utils.Trend, labels utils.Labels) {
+	fmt.Fprintf(w, "\n=== %s ===\n", labels.Trend.Title)
+	header := append(append([]string{labels.Trend.Metric}, trend.Dates...), labels.Trend.Delta)
+	rows := [][]string{header}
+	for _, metric := range trend.Metrics {
+		row := []string{utils.TrendLabel(labels, metric.Key)}
+		for _, value := range metric.Values {
+			row = append(row, fmt.Sprintf("%.1f", value))
+		}
+		rows = append(rows, append(row, trendArrow(metric)))
+	}
+	writeTable(w, rows)
+}
+
+// trendArrow formats the evolution of a metric, e.g. "▲ +2.5 (-)" for a
+// metric that increased and got worse.
+func trendArrow(metric utils.TrendMetric) string {
+	if metric.Delta == 0 {
+		return "="
+	}
+	direction, verdict := "▼", "(-)"
+	if metric.Delta > 0 {
+		direction = "▲"
+	}
+	if metric.Improved() {
+		verdict = "(+)"
+	}
+	return fmt.Sprintf("%s %+.1f %s", direction, metric.Delta, verdict)
+}
+
+// writeTable prints rows as columns aligned on their widest cell, the first
+// row being the header.
+func writeTable(w io.Writer, rows [][]string) {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i > 0 {
+				fmt.Fprint(w, "  ")
+			}
+			fmt.Fprint(w, cell)
+			if i < len(row)-1 {
+				fmt.Fprint(w, strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// writeBuiltin prints the findings on well-known accounts, one line per
+// account or per built-in account whose hash is shared.
+func writeBuiltin(w io.Writer, builtin utils.BuiltinStats, labels utils.Labels) {
+	for _, account := range builtin.GuestEmpty {
+		fmt.Fprintf(w, "%s : %s\n", labels.Builtin.GuestEmpty, account)
+	}
+	for _, shared := range builtin.AdminReused {
+		fmt.Fprintf(w, "%s : %s -> %s\n", labels.Builtin.AdminReused, shared.Builtin, strings.Join(shared.Accounts, ", "))
 	}
+	for _, shared := range builtin.KrbtgtReused {
+		fmt.Fprintf(w, "%s : %s -> %s\n", labels.Builtin.KrbtgtReused, shared.Builtin, strings.Join(shared.Accounts, ", "))
+	}
+}
 
+// writeAdminLinks prints the number of linked accounts, then every pair
+// sharing its NT hash or a password variation.
+func writeAdminLinks(w io.Writer, links utils.AdminLinks, labels utils.Labels) {
+	width := utils.MaxLabelLength(labels.AdminLinks.Pairs, labels.AdminLinks.Shared)
+	fmt.Fprintf(w, "%-*s : %d\n", width, labels.AdminLinks.Pairs, links.Pairs)
+	fmt.Fprintf(w, "%-*s : %d\n", width, labels.AdminLinks.Shared, len(links.Shared))
+	if len(links.Shared) > 0 {
+		fmt.Fprintln(w)
+	}
+	for _, pair := range links.Shared {
+		detail := labels.AdminLinks.SameHash
+		if !pair.SameHash {
+			detail = pair.PersonalPassword + " -> " + pair.AdminPassword
+		}
+		fmt.Fprintf(w, "%s <-> %s : %s\n", pair.Personal, pair.Admin, detail)
+	}
+}
+
+// writePrivileged prints one aligned row per privileged account: qualified
+// username, groups and cracked password (empty when not cracked).
+func writePrivileged(w io.Writer, accounts []utils.Account, labels utils.Labels) {
+	userWidth := len(labels.Privileged.Account)
+	groupWidth := len(labels.Privileged.Groups)
+	for _, a := range accounts {
+		userWidth = max(userWidth, len(a.Name()))
+		groupWidth = max(groupWidth, len(strings.Join(a.Groups, ", ")))
+	}
+
+	fmt.Fprintf(w, "%-*s  %-*s  %s\n", userWidth, labels.Privileged.Account, groupWidth, labels.Privileged.Groups, labels.Privileged.Password)
+	for _, a := range accounts {
+		fmt.Fprintf(w, "%-*s  %-*s  %s\n", userWidth, a.Name(), groupWidth, strings.Join(a.Groups, ", "), a.Password)
+	}
+}
+
+// writePasswordSections writes the sections computed from cracked
+// passwords (reuse, length, complexity, occurrences, patterns, most reused).
+func writePasswordSections(f io.Writer, stats utils.Stats, top int, labels utils.Labels) {
 	lengthWidth := utils.MaxLabelLength(
 		labels.Length.Short,
 		labels.Length.Exact8,
@@ -66,12 +370,17 @@ func ToText(stats utils.Stats, outputDir string, top int, labels utils.Labels) e
 	}
 
 	// Length analysis
+	weighted := stats.Weighted
+	if weighted.PerAccount {
+		fmt.Fprintf(f, "\n%s\n", labels.Weighted.Identical)
+	}
 	fmt.Fprintf(f, "\n=== %s ===\n", labels.Length.Title)
-	fmt.Fprintf(f, fmt.Sprintf("%%-%ds : %%d\n", lengthWidth), labels.Length.Short, utils.SumLengthRange(stats.Lengths, 0, 7))
-	fmt.Fprintf(f, fmt.Sprintf("%%-%ds : %%d\n", lengthWidth), labels.Length.Exact8, stats.Lengths[8])
-	fmt.Fprintf(f, fmt.Sprintf("%%-%ds : %%d\n", lengthWidth), labels.Length.Exact9, stats.Lengths[9])
-	fmt.Fprintf(f, fmt.Sprintf("%%-%ds : %%d\n", lengthWidth), labels.Length.Exact10, stats.Lengths[10])
-	fmt.Fprintf(f, fmt.Sprintf("%%-%ds : %%d\n", lengthWidth), labels.Length.Long, utils.SumLengthRange(stats.Lengths, 11, 100))
+	writeWeightedHeader(f, lengthWidth, weighted, labels)
+	writeRow(f, lengthWidth, labels.Length.Short, utils.SumLengthRange(stats.Lengths, 0, 7), weighted, utils.SumLengthRange(weighted.Lengths, 0, 7))
+	writeRow(f, lengthWidth, labels.Length.Exact8, stats.Lengths[8], weighted, weighted.Lengths[8])
+	writeRow(f, lengthWidth, labels.Length.Exact9, stats.Lengths[9], weighted, weighted.Lengths[9])
+	writeRow(f, lengthWidth, labels.Length.Exact10, stats.Lengths[10], weighted, weighted.Lengths[10])
+	writeRow(f, lengthWidth, labels.Length.Long, utils.SumLengthRange(stats.Lengths, 11, 100), weighted, utils.SumLengthRange(weighted.Lengths, 11, 100))
 
 	complexityWidth := utils.MaxLabelLength(
 		labels.Complexity.One,
@@ -82,10 +391,11 @@ func ToText(stats utils.Stats, outputDir string, top int, labels utils.Labels) e
 
 	// Complexity analysis
 	fmt.Fprintf(f, "\n=== %s ===\n", labels.Complexity.Title)
-	fmt.Fprintf(f, fmt.Sprintf("%%-%ds : %%d\n", complexityWidth), labels.Complexity.One, stats.Complexity[1])
-	fmt.Fprintf(f, fmt.Sprintf("%%-%ds : %%d\n", complexityWidth), labels.Complexity.Two, stats.Complexity[2])
-	fmt.Fprintf(f, fmt.Sprintf("%%-%ds : %%d\n", complexityWidth), labels.Complexity.Three, stats.Complexity[3])
-	fmt.Fprintf(f, fmt.Sprintf("%%-%ds : %%d\n", complexityWidth), labels.Complexity.Four, stats.Complexity[4])
+	writeWeightedHeader(f, complexityWidth, weighted, labels)
+	writeRow(f, complexityWidth, labels.Complexity.One, stats.Complexity[1], weighted, weighted.Complexity[1])
+	writeRow(f, complexityWidth, labels.Complexity.Two, stats.Complexity[2], weighted, weighted.Complexity[2])
+	writeRow(f, complexityWidth, labels.Complexity.Three, stats.Complexity[3], weighted, weighted.Complexity[3])
+	writeRow(f, complexityWidth, labels.Complexity.Four, stats.Complexity[4], weighted, weighted.Complexity[4])
 
 	// Occurrences analysis
 	fmt.Fprintf(f, "\n=== "+labels.Occurrences.Title+" ===\n")
@@ -94,8 +404,9 @@ func ToText(stats utils.Stats, outputDir string, top int, labels utils.Labels) e
 	if len(sortedWords) < top {
 		top = len(sortedWords)
 	}
+	writeWeightedHeader(f, maxLenWords, weighted, labels)
 	for _, s := range sortedWords[:top] {
-		fmt.Fprintf(f, "%-*s : %d\n", maxLenWords, s.Key, s.Value)
+		writeRow(f, maxLenWords, s.Key, s.Value, weighted, weighted.TokenCount[s.Key])
 	}
 
 	// Pattern analysis
@@ -107,8 +418,9 @@ func ToText(stats utils.Stats, outputDir string, top int, labels utils.Labels) e
 	if len(sortedPattern) < top {
 		top = len(sortedPattern)
 	}
+	writeWeightedHeader(f, maxLenPattern, weighted, labels)
 	for _, s := range sortedPattern[:top] {
-		fmt.Fprintf(f, "%-*s : %d\n", maxLenPattern, s.Key, s.Value)
+		writeRow(f, maxLenPattern, s.Key, s.Value, weighted, weighted.Patterns[s.Key])
 	}
 
 	// Most reuse analysis
@@ -123,5 +435,129 @@ func ToText(stats utils.Stats, outputDir string, top int, labels utils.Labels) e
 	for _, s := range sortedReuse[:top] {
 		fmt.Fprintf(f, "%-*s : %d\n", maxLen, s.Key, s.Value)
 	}
-	return nil
+}
+
+// writeHistory prints the password history counters followed by the
+// accounts behind each finding.
+func writeHistory(w io.Writer, history utils.HistoryStats, labels utils.Labels) {
+	width := utils.MaxLabelLength(
+		labels.History.Accounts,
+		labels.History.ReusedCurrent,
+		labels.History.Cycling,
+		labels.History.Incremental,
+	)
+	fmt.Fprintf(w, "%-*s : %d\n", width, labels.History.Accounts, history.Accounts)
+	fmt.Fprintf(w, "%-*s : %d\n", width, labels.History.ReusedCurrent, len(history.ReusedCurrent))
+	fmt.Fprintf(w, "%-*s : %d\n", width, labels.History.Cycling, len(history.Cycling))
+	fmt.Fprintf(w, "%-*s : %d\n", width, labels.History.Incremental, len(history.Incremental))
+
+	if len(history.ReusedCurrent) > 0 {
+		fmt.Fprintf(w, "\n-- %s --\n", labels.History.ReusedCurrent)
+		for _, account := range history.ReusedCurrent {
+			fmt.Fprintln(w, account)
+		}
+	}
+	if len(history.Cycling) > 0 {
+		fmt.Fprintf(w, "\n-- %s --\n", labels.History.Cycling)
+		for _, account := range history.Cycling {
+			fmt.Fprintln(w, account)
+		}
+	}
+	if len(history.Incremental) > 0 {
+		fmt.Fprintf(w, "\n-- %s --\n", labels.History.Incremental)
+		for _, change := range history.Incremental {
+			fmt.Fprintf(w, "%s : %s -> %s\n", change.Account, change.Previous, change.Current)
+		}
+	}
+}
+
+// writeAge prints the password-age distribution, then the accounts whose
+// password was never set or is older than the configured threshold.
+func writeAge(w io.Writer, age utils.AgeStats, labels utils.Labels) {
+	names := []string{
+		labels.Age.Under90,
+		labels.Age.Under180,
+		labels.Age.Under365,
+		labels.Age.Under730,
+		labels.Age.Under1825,
+		labels.Age.Over1825,
+	}
+	width := utils.MaxLabelLength(append(names, labels.Age.Known, labels.Age.NeverSet, labels.Age.Older)...)
+	for i, name := range names {
+		fmt.Fprintf(w, "%-*s : %d\n", width, name, age.Buckets[i])
+	}
+	fmt.Fprintf(w, "%-*s : %d\n", width, labels.Age.Known, age.Known)
+	fmt.Fprintf(w, "%-*s : %d\n", width, labels.Age.NeverSet, len(age.NeverSet))
+	fmt.Fprintf(w, "%-*s : %d\n", width, labels.Age.Older, len(age.Older))
+
+	if len(age.NeverSet) > 0 {
+		fmt.Fprintf(w, "\n-- %s --\n", labels.Age.NeverSet)
+		for _, account := range age.NeverSet {
+			fmt.Fprintln(w, account)
+		}
+	}
+	if len(age.Older) > 0 {
+		fmt.Fprintf(w, "\n-- %s --\n", labels.Age.Older)
+		for _, account := range age.Older {
+			fmt.Fprintln(w, account)
+		}
+	}
+}
+
+// writeWeightedHeader names the two value columns printed by writeRow when
+// account-weighted statistics are available. Nothing is written otherwise.
+func writeWeightedHeader(w io.Writer, width int, weighted utils.WeightedStats, labels utils.Labels) {
+	if !weighted.Enabled {
+		return
+	}
+	fmt.Fprintf(w, "%-*s   %-14s %s\n", width, "", labels.Weighted.Unweighted, labels.Weighted.Weighted)
+}
+
+// writeRow prints an aligned `label : value` line. When account-weighted
+// statistics are available, the weighted value is printed side by side.
+func writeRow(w io.Writer, width int, label string, value int, weighted utils.WeightedStats, weightedValue int) {
+	if !weighted.Enabled {
+		fmt.Fprintf(w, "%-*s : %d\n", width, label, value)
+		return
+	}
+	fmt.Fprintf(w, "%-*s : %-14d %d\n", width, label, value, weightedValue)
+}
+
+// writeAccounts prints one aligned row per cracked account: qualified
+// username, RID, password and reuse group (empty when the hash is unique).
+func writeAccounts(w io.Writer, accounts []utils.Account, labels utils.Labels) {
+	userWidth := len(labels.Accounts.Username)
+	passWidth := len(labels.Accounts.Password)
+	for _, a := range accounts {
+		if !a.Cracked {
+			continue
+		}
+		userWidth = max(userWidth, len(a.Name()))
+		passWidth = max(passWidth, len(a.Password))
+	}
+
+	fmt.Fprintf(w, "%-*s  %-6s  %-*s  %s\n", userWidth, labels.Accounts.Username, labels.Accounts.RID, passWidth, labels.Accounts.Password, labels.Accounts.Group)
+	for _, a := range accounts {
+		if !a.Cracked {
+			continue
+		}
+		group := ""
+		if a.ReuseGroup > 0 {
+			group = fmt.Sprintf("#%d", a.ReuseGroup)
+		}
+		fmt.Fprintf(w, "%-*s  %-6d  %-*s  %s\n", userWidth, a.Name(), a.RID, passWidth, a.Password, group)
+	}
+}
+
+// writeClusters prints every reuse cluster, largest first: group number,
+// size, shared password (or NT hash when not cracked) and member accounts.
+func writeClusters(w io.Writer, clusters []utils.ReuseCluster, labels utils.Labels) {
+	for _, c := range clusters {
+		secret := c.Password
+		if !c.Cracked {
+			secret = labels.Clusters.Hash + " " + c.NTHash
+		}
+		fmt.Fprintf(w, "#%-4d %s: %-5d %s\n", c.Group, labels.Clusters.Size, c.Size, secret)
+		fmt.Fprintf(w, "      %s\n", strings.Join(c.Accounts, ", "))
+	}
 }
@@ -8,7 +8,9 @@ require (
 	github.com/leaanthony/spinner v0.5.4
 	github.com/xuri/excelize/v2 v2.9.1
 	github.com/yarlson/pin v0.9.1
+	golang.org/x/crypto v0.38.0
 	golang.org/x/image v0.25.0
+	golang.org/x/text v0.26.0
 )
 
 require (
@@ -27,8 +29,6 @@ require (
 	github.com/tiendc/go-deepcopy v1.6.0 // indirect
 	github.com/xuri/efp v0.0.1 // indirect
 	github.com/xuri/nfp v0.0.1 // indirect
-	golang.org/x/crypto v0.38.0 // indirect
 	golang.org/x/net v0.40.0 // indirect
 	golang.org/x/sys v0.33.0 // indirect
-	golang.org/x/text v0.26.0 // indirect
 )
@@ -10,12 +10,15 @@ import (
 	htmltemplate "html/template"
 	"image"
 	"image/png"
+	"io/fs"
 	"math"
 	"os"
+	assets "password-analyzer"
 	"reflect"
 	"sort"
 	"strings"
 	ttemplate "text/template"
+	"time"
 
 	"golang.org/x/image/draw"
 
@@ -44,30 +47,317 @@ type Cells struct {
 }
 
 type HashStats struct {
-	TotalNTLMHashes  int
-	UniqueNTLMHashes int
-	ReusedNTLMHashes int
-	IsLM             int
-	IsHash           bool
-	EmptyNTLMHashes  int
-	UserEqualHash    []string // Users with username equal hash
+	TotalNTLMHashes  int      `json:"totalNTLMHashes"`
+	UniqueNTLMHashes int      `json:"uniqueNTLMHashes"`
+	ReusedNTLMHashes int      `json:"reusedNTLMHashes"`
+	IsLM             int      `json:"isLM"`
+	IsHash           bool     `json:"isHash"`
+	EmptyNTLMHashes  int      `json:"emptyNTLMHashes"`
+	UserEqualHash    []string `json:"userEqualHash"`    // Users with username equal hash
+	Disabled         int      `json:"disabled"`         // Disabled accounts found in the hash file
+	DisabledCracked  int      `json:"disabledCracked"`  // Disabled accounts whose password was cracked
+	DisabledIncluded bool     `json:"disabledIncluded"` // True when disabled accounts are part of the statistics
+	Machines         int      `json:"machines"`         // Machine accounts found in the hash file
+	MachinesIncluded bool     `json:"machinesIncluded"` // True when machine accounts are part of the statistics
+}
+
+// AgeStats summarises the password age of the accounts whose pwdLastSet was
+// exported by secretsdump.
+type AgeStats struct {
+	Known    int      `json:"known"`    // Accounts with a known pwdLastSet
+	Buckets  []int    `json:"buckets"`  // Accounts per age bucket (< 90 days, < 180 days, < 1 year, < 2 years, < 5 years, older)
+	NeverSet []string `json:"neverSet"` // Accounts whose password was never set
+	Older    []string `json:"older"`    // Accounts whose password is older than MaxAge days
+	MaxAge   int      `json:"maxAge"`   // Age threshold, in days, used for Older
+}
+
+// Account holds everything known about a single pwdump entry once the
+// cracked plaintexts have been joined to it by NT hash.
+type Account struct {
+	Domain     string         `json:"domain"`     // Domain prefix (e.g. CORP.LAB), empty when absent
+	Username   string         `json:"username"`   // Bare account name without the domain prefix
+	RID        int            `json:"rid"`        // Relative identifier
+	LMHash     string         `json:"-"`          // LM hash, lower-case
+	NTHash     string         `json:"-"`          // NT hash, lower-case
+	Password   string         `json:"password"`   // Cracked plaintext, empty when unknown
+	Cracked    bool           `json:"cracked"`    // True when the NT hash has a known plaintext
+	ReuseGroup int            `json:"reuseGroup"` // Group of accounts sharing this NT hash, 0 when unique
+	History    []HistoryEntry `json:"history"`    // Previous passwords (secretsdump -history), most recent first
+	Status     string         `json:"status"`     // Account status (secretsdump -user-status), empty when unknown
+	PwdLastSet time.Time      `json:"pwdLastSet"` // Last password change (secretsdump -pwd-last-set), zero when unknown
+	NeverSet   bool           `json:"neverSet"`   // True when pwdLastSet is reported as "never"
+	Groups     []string       `json:"groups"`     // Privileged groups the account belongs to (-groups)
+	Class      string         `json:"class"`      // Account class (machine, service, admin, user …)
+}
+
+// Account classes assigned by analysis.Classify.
+const (
+	ClassMachine = "machine"
+	ClassService = "service"
+	ClassAdmin   = "admin"
+	ClassUser    = "user"
+)
+
+// Machine reports whether the account was classified as a machine account.
+func (a Account) Machine() bool {
+	return a.Class == ClassMachine
+}
+
+// Privileged reports whether the account belongs to at least one of the
+// groups of the group membership file.
+func (a Account) Privileged() bool {
+	return len(a.Groups) > 0
+}
+
+// Disabled reports whether secretsdump flagged the account as disabled.
+func (a Account) Disabled() bool {
+	return strings.EqualFold(a.Status, "Disabled")
+}
+
+// HistoryEntry is a previous password of an account, as exported by
+// secretsdump with the -history option.
+type HistoryEntry struct {
+	NTHash   string `json:"-"`        // NT hash, lower-case
+	Password string `json:"password"` // Cracked plaintext, empty when unknown
+	Cracked  bool   `json:"cracked"`  // True when the NT hash has a known plaintext
+}
+
+// HistoryChange records two consecutive passwords of the same account.
+type HistoryChange struct {
+	Account  string `json:"account"`  // Qualified account name
+	Previous string `json:"previous"` // Older plaintext
+	Current  string `json:"current"`  // Newer plaintext
+}
+
+// HistoryStats summarises how accounts reuse their previous passwords.
+type HistoryStats struct {
+	Accounts      int             `json:"accounts"`      // Accounts with at least one previous password
+	ReusedCurrent []string        `json:"reusedCurrent"` // Accounts whose current hash is also in their history
+	Cycling       []string        `json:"cycling"`       // Accounts rotating through a small set of passwords
+	Incremental   []HistoryChange `json:"incremental"`   // Predictable changes such as Spring2023! -> Spring2024!
+}
+
+// Name returns the `DOMAIN\username` form of the account, or the bare
+// username when the hash file carried no domain prefix.
+func (a Account) Name() string {
+	if a.Domain == "" {
+		return a.Username
+	}
+	return a.Domain + "\\" + a.Username
+}
+
+// Name returns the RIDs of the range (`1104 - 1320`), preceded by its domain
+// when set (`CORP 1104 - 1320`).
+func (r RIDRange) Name() string {
+	name := fmt.Sprintf("%d - %d", r.From, r.To)
+	if r.Domain == "" {
+		return name
+	}
+	return r.Domain + " " + name
+}
+
+// SharedHash lists the accounts sharing the NT hash of a built-in account.
+type SharedHash struct {
+	Builtin  string   `json:"builtin"`  // Qualified name of the built-in account
+	Accounts []string `json:"accounts"` // Other accounts with the same NT hash
+}
+
+// BuiltinStats gathers the findings on well-known accounts, identified by
+// their RID.
+type BuiltinStats struct {
+	GuestEmpty   []string     `json:"guestEmpty"`   // Enabled Guest accounts (RID 501) with an empty password
+	AdminReused  []SharedHash `json:"adminReused"`  // Accounts sharing the NT hash of an Administrator (RID 500)
+	KrbtgtReused []SharedHash `json:"krbtgtReused"` // Accounts sharing the NT hash of krbtgt (RID 502)
+	RIDRanges    []RIDRange   `json:"ridRanges"`    // Crack rate per RID range, oldest accounts first
+}
+
+// AdminPair links a personal account to an administration account of the
+// same user whose password is shared or only differs by a suffix.
+type AdminPair struct {
+	Personal         string `json:"personal"`         // Qualified name of the personal account
+	Admin            string `json:"admin"`            // Qualified name of the administration account
+	SameHash         bool   `json:"sameHash"`         // True when both accounts share the same NT hash
+	PersonalPassword string `json:"personalPassword"` // Cracked password of the personal account, when they differ
+	AdminPassword    string `json:"adminPassword"`    // Cracked password of the administration account, when they differ
+}
+
+// AdminLinks summarises the pairs of personal and administration accounts.
+type AdminLinks struct {
+	Pairs  int         `json:"pairs"`  // Personal/administration account pairs found
+	Shared []AdminPair `json:"shared"` // Pairs sharing a password or a variation of it
+}
+
+// UnchangedStats lists the accounts whose password did not change since a
+// previous audit (-prev).
+type UnchangedStats struct {
+	Enabled  bool     `json:"enabled"`  // True when a previous hash file was supplied
+	Matched  int      `json:"matched"`  // Accounts found in both hash files
+	Accounts []string `json:"accounts"` // Accounts with the same NT hash in both hash files
+	Cracked  int      `json:"cracked"`  // Unchanged accounts whose password was cracked
+}
+
+// RiskInput is one of the percentages averaged into the risk score.
+type RiskInput struct {
+	Name  string  `json:"name"`  // "reuse", "lm", "complexity", "length" or "crackRate"
+	Value float64 `json:"value"` // Percentage, 0 to 100
+}
+
+// Account findings, in report order.
+const (
+	FindingUserAsPassword = "userAsPassword" // Password equal to the username
+	FindingLM             = "lm"             // LM hash stored
+	FindingEmpty          = "empty"          // Empty NT hash
+	FindingReused         = "reused"         // NT hash shared with other accounts
+	FindingCracked        = "cracked"        // Password cracked
+	FindingTopTokens      = "topTokens"      // Password containing a top keyword
+)
+
+// Finding lists the accounts affected by one finding, for remediation
+// exports. Details holds, for some findings, one detail per account (e.g.
+// the keyword found in the password).
+type Finding struct {
+	Key      string
+	Accounts []Account
+	Details  []string
+}
+
+// SnapshotVersion is the version of the snapshot.json format, increased on
+// every incompatible change of Stats.
+const SnapshotVersion = 1
+
+// Snapshot holds the statistics of one run. It is saved as snapshot.json in
+// the output directory, to be compared with the next audits.
+type Snapshot struct {
+	Version int       `json:"version"`
+	Date    time.Time `json:"date"`
+	Stats   Stats     `json:"stats"`
+}
+
+// TrendMetric is one key metric of several audits, oldest first.
+type TrendMetric struct {
+	Key            string    `json:"key"`            // Metric name, localised by TrendLabel
+	Group          string    `json:"group"`          // Chart of the metric ("global", "length", "complexity")
+	Values         []float64 `json:"values"`         // One value per audit
+	Delta          float64   `json:"delta"`          // Last value minus the previous one
+	HigherIsBetter bool      `json:"higherIsBetter"` // Direction of an improvement
+}
+
+// Previous returns the value of the metric in the previous audit.
+func (m TrendMetric) Previous() float64 {
+	return m.Values[len(m.Values)-2]
+}
+
+// Last returns the value of the metric in the latest audit.
+func (m TrendMetric) Last() float64 {
+	return m.Values[len(m.Values)-1]
+}
+
+// Improved reports whether the metric got better since the previous audit.
+func (m TrendMetric) Improved() bool {
+	if m.HigherIsBetter {
+		return m.Delta > 0
+	}
+	return m.Delta < 0
+}
+
+// Trend compares the key metrics of two or more audits.
+type Trend struct {
+	Dates   []string      `json:"dates"` // Audit dates, oldest first
+	Metrics []TrendMetric `json:"metrics"`
+}
+
+// Metric returns the metric named key, or a metric without values when it
+// could not be compared (e.g. crack rate without a hash file).
+func (t Trend) Metric(key string) TrendMetric {
+	for _, metric := range t.Metrics {
+		if metric.Key == key {
+			return metric
+		}
+	}
+	return TrendMetric{Key: key}
+}
+
+// Has reports whether the metrics of group could be compared.
+func (t Trend) Has(group string) bool {
+	for _, metric := range t.Metrics {
+		if metric.Group == group {
+			return true
+		}
+	}
+	return false
+}
+
+// RIDRange counts the cracked accounts of a range of RIDs.
+type RIDRange struct {
+	Domain   string `json:"domain"`   // Domain of the accounts, empty when the hash file has a single domain
+	From     int    `json:"from"`     // Lowest RID of the range
+	To       int    `json:"to"`       // Highest RID of the range
+	Accounts int    `json:"accounts"` // Accounts in the range
+	Cracked  int    `json:"cracked"`  // Cracked accounts in the range
+}
+
+// ReuseCluster lists the accounts sharing a single NT hash.
+type ReuseCluster struct {
+	Group    int      `json:"group"`    // Reuse group of the accounts (Account.ReuseGroup)
+	NTHash   string   `json:"-"`        // Shared NT hash, lower-case
+	Accounts []string `json:"accounts"` // Qualified names of the accounts sharing the hash
+	Size     int      `json:"size"`     // Number of accounts in the cluster
+	Cracked  bool     `json:"cracked"`  // True when the shared password is known
+	Password string   `json:"password"` // Shared plaintext, empty when not cracked
+}
+
+// WeightedStats holds the password distributions counted once per account
+// of the hash file instead of once per line of the password file, so that a
+// password shared by many accounts weighs accordingly.
+type WeightedStats struct {
+	Enabled      bool           `json:"enabled"`      // True when weighted statistics were computed
+	PerAccount   bool           `json:"perAccount"`   // True when the statistics were already per account (-pot): both are the same
+	CrackedCount int            `json:"crackedCount"` // Number of cracked accounts
+	Lengths      map[int]int    `json:"lengths"`      // Password lengths per account
+	Complexity   map[int]int    `json:"complexity"`   // Password complexity per account
+	Patterns     map[string]int `json:"patterns"`     // Patterns per account
+	TokenCount   map[string]int `json:"tokenCount"`   // Words most used per account
+}
+
+// Breakdown holds the statistics of a named subset of the accounts, such as
+// an account class.
+type Breakdown struct {
+	Name  string `json:"name"`  // Subset name, e.g. the class name
+	Stats Stats  `json:"stats"` // Statistics of the subset (see analysis.SubsetStats)
 }
 
 // Stats contains the statistics resulting from password analysis.
 type Stats struct {
-	CrackedCount      int            // Total number of Crackedpasswords
-	TotalCount        int            // Total number of passwords/hashes
-	Lengths           map[int]int    // Password lengths
-	Complexity        map[int]int    // Password complexity
-	Patterns          map[string]int // Patterns (e.g., "l" lower, "u" uper, "d" decimal, "s" special)
-	Mostreuse         map[string]int // Password reuse counts
-	CrackedReuseCount int            // Cracked password reuse counts
-	TotalReuseCount   int            // Total password reuse counts
-	TokenCount        map[string]int // words most used
-	Hashes            HashStats      // Hash statistics
-	GlobalPercent     float64        // Global percent
-	Risk              string         // Risk
-	Top               int            // Top number to be displayed
+	CrackedCount      int            `json:"crackedCount"`      // Total number of Crackedpasswords
+	TotalCount        int            `json:"totalCount"`        // Total number of passwords/hashes
+	Lengths           map[int]int    `json:"lengths"`           // Password lengths
+	Complexity        map[int]int    `json:"complexity"`        // Password complexity
+	Patterns          map[string]int `json:"patterns"`          // Patterns (e.g., "l" lower, "u" uper, "d" decimal, "s" special)
+	Mostreuse         map[string]int `json:"mostreuse"`         // Password reuse counts
+	CrackedReuseCount int            `json:"crackedReuseCount"` // Cracked password reuse counts
+	TotalReuseCount   int            `json:"totalReuseCount"`   // Total password reuse counts
+	TokenCount        map[string]int `json:"tokenCount"`        // words most used
+	Hashes            HashStats      `json:"hashes"`            // Hash statistics
+	HashOnly          bool           `json:"hashOnly"`          // No cracked passwords supplied, hash file only
+	Accounts          []Account      `json:"accounts"`          // Per-account records (requires a hash file)
+	Orphans           []string       `json:"orphans"`           // Cracked passwords matching no hash of the hash file
+	Clusters          []ReuseCluster `json:"clusters"`          // Accounts sharing an NT hash, largest cluster first
+	History           HistoryStats   `json:"history"`           // Password history findings (secretsdump -history)
+	Age               AgeStats       `json:"age"`               // Password age (secretsdump -pwd-last-set)
+	PreWin2000        []string       `json:"preWin2000"`        // Machine accounts still using their pre-Windows 2000 default password
+	Builtin           BuiltinStats   `json:"builtin"`           // Findings on well-known accounts (RID 500, 501, 502)
+	AdminLinks        AdminLinks     `json:"adminLinks"`        // Personal and administration accounts sharing a password
+	Unchanged         UnchangedStats `json:"unchanged"`         // Passwords unchanged since the previous audit (-prev)
+	Trend             *Trend         `json:"trend"`             // Comparison with previous snapshots (-compare), nil otherwise
+	Weighted          WeightedStats  `json:"weighted"`          // Account-weighted distributions (optional)
+	Privileged        *Stats         `json:"privileged"`        // Same statistics for privileged accounts only, nil without -groups
+	Classes           []Breakdown    `json:"classes"`           // Same statistics per account class
+	Domains           []Breakdown    `json:"domains"`           // Same statistics per domain, nil for a single domain
+	CrossDomain       []ReuseCluster `json:"crossDomain"`       // NT hashes shared by accounts of several domains
+	GlobalPercent     float64        `json:"globalPercent"`     // Global percent
+	Risk              string         `json:"risk"`              // Risk
+	RiskInputs        []RiskInput    `json:"riskInputs"`        // Percentages averaged into GlobalPercent
+	Top               int            `json:"top"`               // Top number to be displayed
 }
 
 // Labels holds all translation strings structured by category.
@@ -87,6 +377,20 @@ type Labels struct {
 		Patterns     Content `json:"patterns"`
 		Mostreuse    Content `json:"mostreuse"`
 		Reuse        Content `json:"reuse"`
+		Weighted     Content `json:"weighted"`
+		Clusters     Content `json:"clusters"`
+		History      Content `json:"history"`
+		Age          Content `json:"age"`
+		Privileged   Content `json:"privileged"`
+		Classes      Content `json:"classes"`
+		Domains      Content `json:"domains"`
+		CrossDomain  Content `json:"crossDomain"`
+		PreWin2000   Content `json:"preWin2000"`
+		Builtin      Content `json:"builtin"`
+		RIDRanges    Content `json:"ridRanges"`
+		AdminLinks   Content `json:"adminLinks"`
+		Unchanged    Content `json:"unchanged"`
+		Trend        Content `json:"trend"`
 		Remediation  Content `json:"remediation"`
 	} `json:"html"`
 
@@ -143,6 +447,145 @@ type Labels struct {
 		B1     string `json:"B1"`
 	} `json:"Reuse"`
 
+	Accounts struct {
+		Title    string `json:"title"`
+		Short    string `json:"short"`
+		Domain   string `json:"domain"`
+		Username string `json:"username"`
+		RID      string `json:"rid"`
+		Password string `json:"password"`
+		Group    string `json:"group"`
+	} `json:"Accounts"`
+
+	Weighted struct {
+		Title      string `json:"title"`
+		Unweighted string `json:"unweighted"`
+		Weighted   string `json:"weighted"`
+		Identical  string `json:"identical"`
+	} `json:"Weighted"`
+
+	Clusters struct {
+		Title    string `json:"title"`
+		Short    string `json:"short"`
+		Group    string `json:"group"`
+		Size     string `json:"size"`
+		Password string `json:"password"`
+		Hash     string `json:"hash"`
+		Accounts string `json:"accounts"`
+	} `json:"Clusters"`
+
+	History struct {
+		Title         string `json:"title"`
+		Short         string `json:"short"`
+		Accounts      string `json:"accounts"`
+		ReusedCurrent string `json:"reusedCurrent"`
+		Cycling       string `json:"cycling"`
+		Incremental   string `json:"incremental"`
+		Finding       string `json:"finding"`
+		Account       string `json:"account"`
+		Detail        string `json:"detail"`
+	} `json:"History"`
+
+	Orphans struct {
+		Title string `json:"title"`
+		Short string `json:"short"`
+		A1    string `json:"A1"`
+	} `json:"Orphans"`
+
+	Builtin struct {
+		Title        string `json:"title"`
+		Short        string `json:"short"`
+		Finding      string `json:"finding"`
+		Account      string `json:"account"`
+		Detail       string `json:"detail"`
+		GuestEmpty   string `json:"guestEmpty"`
+		AdminReused  string `json:"adminReused"`
+		KrbtgtReused string `json:"krbtgtReused"`
+	} `json:"Builtin"`
+
+	Reset struct {
+		Synopsis    string            `json:"synopsis"`
+		Description string            `json:"description"`
+		LMGuidance  string            `json:"lmGuidance"`
+		Already     string            `json:"already"`
+		Names       map[string]string `json:"names"`
+	} `json:"Reset"`
+
+	Trend struct {
+		Title      string            `json:"title"`
+		Short      string            `json:"short"`
+		Metric     string            `json:"metric"`
+		Delta      string            `json:"delta"`
+		Global     string            `json:"global"`
+		Length     string            `json:"length"`
+		Complexity string            `json:"complexity"`
+		Names      map[string]string `json:"names"`
+	} `json:"Trend"`
+
+	Unchanged struct {
+		Title     string `json:"title"`
+		Short     string `json:"short"`
+		Matched   string `json:"matched"`
+		Unchanged string `json:"unchanged"`
+		Cracked   string `json:"cracked"`
+		A1        string `json:"A1"`
+	} `json:"Unchanged"`
+
+	AdminLinks struct {
+		Title    string `json:"title"`
+		Short    string `json:"short"`
+		Pairs    string `json:"pairs"`
+		Shared   string `json:"shared"`
+		Personal string `json:"personal"`
+		Admin    string `json:"admin"`
+		Detail   string `json:"detail"`
+		SameHash string `json:"sameHash"`
+	} `json:"AdminLinks"`
+
+	RIDRanges struct {
+		Title    string `json:"title"`
+		Short    string `json:"short"`
+		Range    string `json:"range"`
+		Accounts string `json:"accounts"`
+		Cracked  string `json:"cracked"`
+		Rate     string `json:"rate"`
+	} `json:"RIDRanges"`
+
+	PreWin2000 struct {
+		Title string `json:"title"`
+		Short string `json:"short"`
+		A1    string `json:"A1"`
+	} `json:"PreWin2000"`
+
+	Domains struct {
+		Title      string `json:"title"`
+		Short      string `json:"short"`
+		Domain     string `json:"domain"`
+		None       string `json:"none"`
+		Select     string `json:"select"`
+		CrossTitle string `json:"crossTitle"`
+		CrossShort string `json:"crossShort"`
+	} `json:"Domains"`
+
+	Classes struct {
+		Title    string            `json:"title"`
+		Short    string            `json:"short"`
+		Class    string            `json:"class"`
+		Names    map[string]string `json:"names"`
+		Machines string            `json:"machines"`
+	} `json:"Classes"`
+
+	Privileged struct {
+		Title    string `json:"title"`
+		Short    string `json:"short"`
+		Headline string `json:"headline"`
+		Account  string `json:"account"`
+		Groups   string `json:"groups"`
+		Password string `json:"password"`
+		Figure   string `json:"figure"`
+		Value    string `json:"value"`
+	} `json:"Privileged"`
+
 	Hash struct {
 		TotalNTLM     string `json:"totalNTLM"`
 		Cracked       string `json:"cracked"`
@@ -152,8 +595,30 @@ type Labels struct {
 		EmptyNTLM     string `json:"emptyNTLM"`
 		Title         string `json:"title"`
 		UserEqualHash string `json:"userEqualHash"`
+		Disabled      string `json:"disabled"`
+		DisabledCrack string `json:"disabledCracked"`
+		Excluded      string `json:"excluded"`
+		CrackedRate   string `json:"crackedRate"`
 	} `json:"Hash"`
 
+	Age struct {
+		Title     string `json:"title"`
+		Short     string `json:"short"`
+		A1        string `json:"A1"`
+		B1        string `json:"B1"`
+		Under90   string `json:"under90"`
+		Under180  string `json:"under180"`
+		Under365  string `json:"under365"`
+		Under730  string `json:"under730"`
+		Under1825 string `json:"under1825"`
+		Over1825  string `json:"over1825"`
+		Known     string `json:"known"`
+		NeverSet  string `json:"neverSet"`
+		Older     string `json:"older"`
+		Status    string `json:"status"`
+		LastSet   string `json:"lastSet"`
+	} `json:"Age"`
+
 	TotalCracked struct {
 		Title string `json:"title"`
 	} `json:"TotalCracked"`
@@ -163,6 +628,7 @@ type Labels struct {
 	} `json:"Total"`
 
 	Risk struct {
+		Title    string `json:"title"`
 		Low      string `json:"low"`
 		Medium   string `json:"medium"`
 		High     string `json:"high"`
@@ -170,10 +636,19 @@ type Labels struct {
 	} `json:"Risk"`
 }
 
-// Hold all data labels + stats
+// Hold all data labels + stats. Data is the model of the HTML templates
+// (`.Stats`, `.Labels`, `.Run`) and of the JSON report.
 type Data struct {
-	Stats  Stats
-	Labels Labels
+	Stats  Stats       // Statistics of the analysis
+	Labels Labels      // Localised texts of the language file, logos in base 64
+	Run    RunMetadata // Date, language and options of the run
+}
+
+// RunMetadata describes the run that produced the statistics.
+type RunMetadata struct {
+	Date    time.Time         `json:"date"`    // Start of the run
+	Lang    string            `json:"lang"`    // Report language
+	Options map[string]string `json:"options"` // Command-line options, by flag name
 }
 
 // Percent returns part expressed as a percentage of the provided total,
@@ -193,7 +668,7 @@ func Percent(part, total int) float64 {
 func InsertStats(lang string, data Data) error {
 
 	filePath := fmt.Sprintf("lang/%s.json", lang)
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	if _, err := fs.Stat(assets.FS, filePath); err != nil {
 		return fmt.Errorf("Language file not found: %s", lang)
 	}
 
@@ -239,7 +714,7 @@ func InsertStats(lang string, data Data) error {
 		},
 	}
 
-	statsTmpl := ttemplate.Must(ttemplate.New("report").Funcs(funcMap).ParseFiles(filePath))
+	statsTmpl := ttemplate.Must(ttemplate.New("report").Funcs(funcMap).ParseFS(assets.FS, filePath))
 
 	out, err := os.Create("tmp-" + lang + ".json")
 	if err != nil {
@@ -312,7 +787,9 @@ func SplitOutputTypes(raw string) []string {
 }
 
 // SortMapByValueDesc takes a map[string]int and returns a slice of Entry,
-// sorted by Value from highest to lowest.
+// sorted by Value from highest to lowest. Equal values are sorted by Key, so
+// that the order does not depend on map iteration and reports are the same
+// from one run to the next.
 func SortMapByValueDesc(m map[string]int) []Entry {
 	entries := make([]Entry, 0, len(m))
 	for k, v := range m {
@@ -320,12 +797,41 @@ func SortMapByValueDesc(m map[string]int) []Entry {
 	}
 
 	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].Value > entries[j].Value
+		if entries[i].Value != entries[j].Value {
+			return entries[i].Value > entries[j].Value
+		}
+		return entries[i].Key < entries[j].Key
 	})
 
 	return entries
 }
 
+// ClassLabel returns the localised name of an account class, or the class
+// itself for classes added by a rule file.
+func ClassLabel(labels Labels, class string) string {
+	if name := labels.Classes.Names[class]; name != "" {
+		return name
+	}
+	return class
+}
+
+// TrendLabel returns the localised name of a trend metric.
+func TrendLabel(labels Labels, key string) string {
+	if name := labels.Trend.Names[key]; name != "" {
+		return name
+	}
+	return key
+}
+
+// DomainLabel returns the name of a domain, or the localised placeholder for
+// accounts without a domain prefix.
+func DomainLabel(labels Labels, domain string) string {
+	if domain == "" {
+		return labels.Domains.None
+	}
+	return domain
+}
+
 // MaxLabelLength returns the length of the longest string among the supplied
 // label arguments.
 func MaxLabelLength(labels ...string) int {
@@ -351,6 +857,18 @@ func ImageToBase64(path string) (string, error) {
 	return encoded, nil
 }
 
+// AssetToBase64 is ImageToBase64 for an asset (e.g. "img/logo_sysdream.png"),
+// read from the -assets directory or the embedded files but never from the
+// working directory.
+func AssetToBase64(name string) (string, error) {
+	data, err := assets.ReadFile(name)
+	if err != nil {
+		return "", fmt.Errorf("[AssetToBase64] failed to read asset %s: %w", name, err)
+	}
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
 // MaskPassword anonymises a password by keeping the first two and last two
 // characters visible and replacing the characters in between with '*'.
 // If the password length is 4 or less, it is returned unchanged. UTF-8
@@ -375,9 +893,56 @@ func MaskStats(s *Stats) {
 		maskedReuse[MaskPassword(k)] = v
 	}
 	s.Mostreuse = maskedReuse
+	// Mask plaintexts attached to accounts
+	for i := range s.Accounts {
+		s.Accounts[i].Password = MaskPassword(s.Accounts[i].Password)
+		for j := range s.Accounts[i].History {
+			s.Accounts[i].History[j].Password = MaskPassword(s.Accounts[i].History[j].Password)
+		}
+	}
+	// Mask plaintexts of predictable password changes
+	for i := range s.History.Incremental {
+		s.History.Incremental[i].Previous = MaskPassword(s.History.Incremental[i].Previous)
+		s.History.Incremental[i].Current = MaskPassword(s.History.Incremental[i].Current)
+	}
+	// Mask plaintexts shared by reuse clusters
+	for i := range s.Clusters {
+		s.Clusters[i].Password = MaskPassword(s.Clusters[i].Password)
+	}
+	// Mask plaintexts of personal and administration accounts
+	for i := range s.AdminLinks.Shared {
+		s.AdminLinks.Shared[i].PersonalPassword = MaskPassword(s.AdminLinks.Shared[i].PersonalPassword)
+		s.AdminLinks.Shared[i].AdminPassword = MaskPassword(s.AdminLinks.Shared[i].AdminPassword)
+	}
+	// Mask plaintexts that could not be attributed to any account
+	for i := range s.Orphans {
+		s.Orphans[i] = MaskPassword(s.Orphans[i])
+	}
+	// Privileged accounts and classes carry the same plaintexts
+	if s.Privileged != nil {
+		MaskStats(s.Privileged)
+	}
+	for i := range s.Classes {
+		MaskStats(&s.Classes[i].Stats)
+	}
+	for i := range s.Domains {
+		MaskStats(&s.Domains[i].Stats)
+	}
+	for i := range s.CrossDomain {
+		s.CrossDomain[i].Password = MaskPassword(s.CrossDomain[i].Password)
+	}
 	// Occurrence keywords remain visible, do not mask
 }
 
+// MaskFindings masks the passwords of the accounts of findings (-anon).
+func MaskFindings(findings []Finding) {
+	for i := range findings {
+		for j := range findings[i].Accounts {
+			findings[i].Accounts[j].Password = MaskPassword(findings[i].Accounts[j].Password)
+		}
+	}
+}
+
 // SanitizeStats escapes HTML special characters in keys of statistics maps that are rendered
 // into the HTML report. This prevents JavaScript/HTML injection when the keys originate from
 // untrusted sources such as cracked passwords. The values are left untouched so the numerical